@@ -0,0 +1,292 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// BaselineVersion is the version of the baseline format written by this package
+const BaselineVersion = 1
+
+// BaselineState represents the state of a finding compared with the findings of a baseline
+type BaselineState string
+
+const (
+	// BaselineNew is the state of the findings that aren't in the baseline
+	BaselineNew BaselineState = "new"
+
+	// BaselineUnchanged is the state of the findings that are in the baseline, even if they were moved to other lines
+	BaselineUnchanged BaselineState = "unchanged"
+)
+
+// Baseline holds the findings of a previous analysis, so the findings of the next ones can be classified as new or
+// unchanged, and the ones that are no longer found as fixed
+type Baseline struct {
+	Version  int               `json:"version"`
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// BaselineFinding is a finding saved in a baseline. Besides the fingerprint, used to match the findings, it holds
+// some information of the finding to identify it
+type BaselineFinding struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"ruleId"`
+	Filename    string `json:"filename"`
+	Line        int    `json:"line"`
+	CodeSample  string `json:"codeSample"`
+}
+
+// BaselineComparison is the result of comparing findings with a baseline
+type BaselineComparison struct {
+	New       []Finding         // New holds the findings that aren't in the baseline
+	Unchanged []Finding         // Unchanged holds the findings that are in the baseline
+	Fixed     []BaselineFinding // Fixed holds the findings of the baseline that were not found
+}
+
+// NewBaseline creates a baseline with the findings, which should have their fingerprints set by the engine
+func NewBaseline(findings []Finding) *Baseline {
+	baseline := &Baseline{Version: BaselineVersion, Findings: make([]BaselineFinding, 0, len(findings))}
+
+	for index := range findings {
+		baseline.Findings = append(baseline.Findings, BaselineFinding{
+			Fingerprint: getFingerprint(&findings[index]),
+			RuleID:      findings[index].ID,
+			Filename:    findings[index].SourceLocation.Filename,
+			Line:        findings[index].SourceLocation.Line,
+			CodeSample:  findings[index].CodeSample,
+		})
+	}
+
+	return baseline
+}
+
+// ReadBaseline reads a baseline from the JSON file written by Baseline.WriteFile
+func ReadBaseline(path string) (*Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return DecodeBaseline(file)
+}
+
+// DecodeBaseline decodes a baseline from JSON, only baselines with the current BaselineVersion are accepted
+func DecodeBaseline(r io.Reader) (*Baseline, error) {
+	baseline := new(Baseline)
+
+	if err := json.NewDecoder(r).Decode(baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+
+	if baseline.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d, expected %d", baseline.Version, BaselineVersion)
+	}
+
+	return baseline, nil
+}
+
+// WriteFile writes the baseline as JSON into the file, creating or truncating it
+func (b *Baseline) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = b.Encode(file); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// Encode writes the baseline as indented JSON
+func (b *Baseline) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(b)
+}
+
+// Compare classifies the findings as new or unchanged compared with the baseline, setting their BaselineState, and
+// returns the findings of the baseline that were not found as fixed. The findings are matched by their fingerprints,
+// so they tolerate moved lines, and each finding of the baseline matches only one finding, so a new occurrence of a
+// vulnerability already in the baseline is still reported as new
+func (b *Baseline) Compare(findings []Finding) *BaselineComparison {
+	matcher := newBaselineMatcher(b)
+	comparison := new(BaselineComparison)
+
+	for index := range findings {
+		finding := findings[index]
+		finding.BaselineState = matcher.match(getFingerprint(&finding))
+		comparison.add(&finding)
+	}
+
+	comparison.Fixed = matcher.fixed(nil)
+
+	return comparison
+}
+
+// add appends the finding to the new or unchanged findings of the comparison according to its baseline state
+func (c *BaselineComparison) add(finding *Finding) {
+	if finding.BaselineState == BaselineNew {
+		c.New = append(c.New, *finding)
+	} else {
+		c.Unchanged = append(c.Unchanged, *finding)
+	}
+}
+
+// baselineMatcher matches the findings of an analysis with the findings of the baseline, it's safe to be used by the
+// goroutines of the pool. Each finding of the baseline matches only one finding
+type baselineMatcher struct {
+	mutex       sync.Mutex
+	baseline    *Baseline
+	remaining   map[string]int
+	projectPath string
+	files       map[string]bool // files holds the relative paths analyzed by a partial analysis, nil means all files
+}
+
+// newBaselineMatcher creates a matcher for the baseline, it returns nil if the baseline is nil, and a nil matcher
+// doesn't set the baseline state of the findings
+func newBaselineMatcher(baseline *Baseline) *baselineMatcher {
+	if baseline == nil {
+		return nil
+	}
+
+	matcher := &baselineMatcher{baseline: baseline, remaining: map[string]int{}}
+
+	for index := range baseline.Findings {
+		matcher.remaining[baseline.Findings[index].Fingerprint]++
+	}
+
+	return matcher
+}
+
+// match returns the baseline state of the finding with the fingerprint, consuming a match of the baseline
+func (m *baselineMatcher) match(fingerprint string) BaselineState {
+	if m == nil {
+		return ""
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.remaining[fingerprint] == 0 {
+		return BaselineNew
+	}
+
+	m.remaining[fingerprint]--
+
+	return BaselineUnchanged
+}
+
+// onlyFiles restricts the fixed findings to the ones of the files, since a partial analysis, like the analysis of the
+// changed files, can't tell if the findings of the other files were fixed. The names of the files are relative to
+// the project path, and are compared with the file names of the baseline findings relative to it
+func (m *baselineMatcher) onlyFiles(projectPath string, names []string) {
+	if m == nil {
+		return
+	}
+
+	m.projectPath = projectPath
+	m.files = make(map[string]bool, len(names))
+
+	for _, name := range names {
+		m.files[normalizePath(name)] = true
+	}
+}
+
+// relativePath returns the normalized path of the file relative to the project path, which is used to compare the
+// files of the analysis with the file names of the baseline findings
+func (m *baselineMatcher) relativePath(filename string) string {
+	return normalizePath(relativeFindingPath(m.projectPath, filename))
+}
+
+// isAnalyzed checks if the file of a baseline finding was analyzed without errors
+func (m *baselineMatcher) isAnalyzed(filename string, failed map[string]bool) bool {
+	path := m.relativePath(filename)
+
+	return (m.files == nil || m.files[path]) && !failed[path]
+}
+
+// fixed returns the findings of the baseline that were not matched, in the baseline order. The findings of the files
+// with errors are not returned, since the analysis of these files didn't finish, so it's unknown if they were fixed
+func (m *baselineMatcher) fixed(scanErrors []*ScanError) []BaselineFinding {
+	if m == nil {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.unmatchedFindings(m.failedFiles(scanErrors))
+}
+
+// failedFiles returns the relative paths of the files with errors
+func (m *baselineMatcher) failedFiles(scanErrors []*ScanError) map[string]bool {
+	failed := make(map[string]bool, len(scanErrors))
+
+	for _, err := range scanErrors {
+		failed[m.relativePath(err.Path)] = true
+	}
+
+	return failed
+}
+
+// unmatchedFindings returns the findings of the baseline that were not matched in the files analyzed without errors.
+// The remaining matches are not consumed, so the fixed findings can be computed again
+func (m *baselineMatcher) unmatchedFindings(failed map[string]bool) []BaselineFinding {
+	var unmatched []BaselineFinding
+
+	remaining := copyCounts(m.remaining)
+
+	for _, finding := range m.baseline.Findings {
+		if remaining[finding.Fingerprint] > 0 && m.isAnalyzed(finding.Filename, failed) {
+			remaining[finding.Fingerprint]--
+			unmatched = append(unmatched, finding)
+		}
+	}
+
+	return unmatched
+}
+
+// copyCounts returns a copy of the number of remaining matches of each fingerprint
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+
+	for fingerprint, count := range counts {
+		copied[fingerprint] = count
+	}
+
+	return copied
+}
+
+// getFingerprint returns the fingerprint of the finding, computing it from the file name of the finding if it wasn't
+// set by the engine
+func getFingerprint(finding *Finding) string {
+	if finding.Fingerprint != "" {
+		return finding.Fingerprint
+	}
+
+	return Fingerprint(finding.ID, finding.SourceLocation.Filename, finding.CodeSample)
+}
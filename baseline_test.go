@@ -0,0 +1,174 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBaselineTestFinding(id, filename string, line int, codeSample string) Finding {
+	return Finding{
+		ID:             id,
+		CodeSample:     codeSample,
+		SourceLocation: Location{Filename: filename, Line: line},
+		Fingerprint:    Fingerprint(id, filename, codeSample),
+	}
+}
+
+func TestNewBaseline(t *testing.T) {
+	t.Run("Should create the baseline with the fingerprints of the findings", func(t *testing.T) {
+		finding := newBaselineTestFinding("HS-TEST-1", "a.go", 10, "call(a)")
+
+		baseline := NewBaseline([]Finding{finding, {ID: "HS-TEST-2", CodeSample: "call(b)"}})
+
+		assert.Equal(t, BaselineVersion, baseline.Version)
+		assert.Equal(t, []BaselineFinding{
+			{Fingerprint: finding.Fingerprint, RuleID: "HS-TEST-1", Filename: "a.go", Line: 10, CodeSample: "call(a)"},
+			{Fingerprint: Fingerprint("HS-TEST-2", "", "call(b)"), RuleID: "HS-TEST-2", CodeSample: "call(b)"},
+		}, baseline.Findings)
+	})
+}
+
+func TestBaselineFile(t *testing.T) {
+	t.Run("Should write and read the baseline file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		baseline := NewBaseline([]Finding{newBaselineTestFinding("HS-TEST-1", "a.go", 10, "call(a)")})
+
+		require.NoError(t, baseline.WriteFile(path))
+
+		read, err := ReadBaseline(path)
+		assert.NoError(t, err)
+		assert.Equal(t, baseline, read)
+	})
+
+	t.Run("Should return an error when the file doesn't exist", func(t *testing.T) {
+		_, err := ReadBaseline(filepath.Join(t.TempDir(), "baseline.json"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Should return an error for invalid baselines", func(t *testing.T) {
+		_, err := DecodeBaseline(strings.NewReader("{"))
+		assert.Error(t, err)
+
+		_, err = DecodeBaseline(strings.NewReader(`{"version": 2, "findings": []}`))
+		assert.EqualError(t, err, "unsupported baseline version 2, expected 1")
+	})
+}
+
+func TestBaselineCompare(t *testing.T) {
+	baseline := NewBaseline([]Finding{
+		newBaselineTestFinding("HS-TEST-1", "a.go", 10, "call(a)"),
+		newBaselineTestFinding("HS-TEST-1", "a.go", 20, "call(a)"),
+		newBaselineTestFinding("HS-TEST-2", "b.go", 5, "call(b)"),
+	})
+
+	t.Run("Should classify the findings as new, unchanged and fixed", func(t *testing.T) {
+		comparison := baseline.Compare([]Finding{
+			newBaselineTestFinding("HS-TEST-1", "a.go", 12, "call(a)"),
+			newBaselineTestFinding("HS-TEST-1", "a.go", 22, "  call(a)"),
+			newBaselineTestFinding("HS-TEST-1", "a.go", 30, "call(a)"),
+			newBaselineTestFinding("HS-TEST-3", "c.go", 1, "call(c)"),
+		})
+
+		assert.Len(t, comparison.Unchanged, 2)
+		assert.Equal(t, BaselineUnchanged, comparison.Unchanged[0].BaselineState)
+
+		if assert.Len(t, comparison.New, 2) {
+			assert.Equal(t, 30, comparison.New[0].SourceLocation.Line)
+			assert.Equal(t, "HS-TEST-3", comparison.New[1].ID)
+			assert.Equal(t, BaselineNew, comparison.New[1].BaselineState)
+		}
+
+		assert.Equal(t, []BaselineFinding{baseline.Findings[2]}, comparison.Fixed)
+	})
+
+	t.Run("Should report all findings of the baseline as fixed when there are no findings", func(t *testing.T) {
+		assert.Equal(t, baseline.Findings, baseline.Compare(nil).Fixed)
+	})
+}
+
+// nolint:funlen // table of baseline options
+func TestEngineScanWithBaseline(t *testing.T) {
+	project := newTestProject(t, 2)
+	rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+	previous, err := NewEngine(1, ".go").Run(context.Background(), project, rule)
+	require.NoError(t, err)
+	require.Len(t, previous, 2)
+
+	fixed := newBaselineTestFinding("HS-TEST-2", "removed.go", 1, "call(b)")
+	baseline := NewBaseline(append(previous[:1:1], fixed))
+
+	t.Run("Should classify the findings with the baseline", func(t *testing.T) {
+		result, err := NewEngineWithOptions(2, []string{".go"}, WithBaseline(baseline)).
+			Scan(context.Background(), project, rule)
+		require.NoError(t, err)
+
+		states := map[string]BaselineState{}
+		for _, finding := range result.Findings {
+			states[filepath.Base(finding.SourceLocation.Filename)] = finding.BaselineState
+		}
+
+		assert.Equal(t, map[string]BaselineState{
+			filepath.Base(previous[0].SourceLocation.Filename): BaselineUnchanged,
+			filepath.Base(previous[1].SourceLocation.Filename): BaselineNew,
+		}, states)
+		assert.Equal(t, []BaselineFinding{baseline.Findings[1]}, result.Fixed)
+	})
+
+	t.Run("Should drop the unchanged findings with WithoutUnchangedFindings", func(t *testing.T) {
+		findings, err := NewEngineWithOptions(2, []string{".go"}, WithBaseline(baseline), WithoutUnchangedFindings()).
+			Run(context.Background(), project, rule)
+		require.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, previous[1].Fingerprint, findings[0].Fingerprint)
+			assert.Equal(t, BaselineNew, findings[0].BaselineState)
+		}
+	})
+
+	t.Run("Should not report the findings of files with errors as fixed with ContinueOnError", func(t *testing.T) {
+		failed := NewBaseline([]Finding{
+			newBaselineTestFinding("HS-TEST-2", filepath.Join(project, "file0.go"), 1, "call(b)"),
+			newBaselineTestFinding("HS-TEST-2", filepath.Join(project, "file1.go"), 1, "call(b)"),
+		})
+
+		result, err := NewEngineWithOptions(2, []string{".go"}, WithBaseline(failed), WithErrorPolicy(ContinueOnError)).
+			Scan(context.Background(), project, &pathErrorRuleMock{errorPath: "file0.go"})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+
+		assert.Equal(t, failed.Findings[1:], result.Fixed)
+	})
+
+	t.Run("Should not set the baseline state without a baseline", func(t *testing.T) {
+		result, err := NewEngine(2, ".go").Scan(context.Background(), project, rule)
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Fixed)
+
+		for _, finding := range result.Findings {
+			assert.Empty(t, finding.BaselineState)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import "bytes"
+
+const (
+	// DefaultBinarySniffSize is the number of bytes from the beginning of the content inspected to detect binary
+	// files, the same used by git
+	DefaultBinarySniffSize = 8000
+
+	// maxControlBytesRatio is the maximum ratio of control bytes that a text content can have
+	maxControlBytesRatio = 0.1
+)
+
+// magicNumbers holds the signatures found in the beginning of common binary file formats
+var magicNumbers = [][]byte{
+	[]byte("\x7fELF"),                          // Linux executables and libraries
+	[]byte("MZ"),                               // Windows executables and libraries
+	[]byte("\xfe\xed\xfa\xce"),                 // Mach-O 32 bits
+	[]byte("\xfe\xed\xfa\xcf"),                 // Mach-O 64 bits
+	[]byte("\xce\xfa\xed\xfe"),                 // Mach-O 32 bits, reverse byte order
+	[]byte("\xcf\xfa\xed\xfe"),                 // Mach-O 64 bits, reverse byte order
+	[]byte("\xca\xfe\xba\xbe"),                 // Java class and Mach-O universal binaries
+	[]byte("dex\n"),                            // Android Dalvik executables
+	[]byte("BC\xc0\xde"),                       // LLVM bitcode
+	[]byte("!<arch>\n"),                        // static libraries
+	[]byte("\x89PNG\r\n\x1a\n"),                // PNG images
+	[]byte("\xff\xd8\xff"),                     // JPEG images
+	[]byte("GIF87a"),                           // GIF images
+	[]byte("GIF89a"),                           // GIF images
+	[]byte("II*\x00"),                          // TIFF images, little endian
+	[]byte("MM\x00*"),                          // TIFF images, big endian
+	[]byte("8BPS"),                             // Photoshop documents
+	[]byte("RIFF"),                             // WAV, AVI and WebP
+	[]byte("OggS"),                             // Ogg media
+	[]byte("fLaC"),                             // FLAC audio
+	[]byte("MThd"),                             // MIDI audio
+	[]byte("wOFF"),                             // WOFF fonts
+	[]byte("wOF2"),                             // WOFF2 fonts
+	[]byte("%PDF-"),                            // PDF documents
+	[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), // legacy Microsoft Office documents and MSI packages
+	[]byte("PK\x03\x04"),                       // ZIP archives, including jar, apk and Office documents
+	[]byte("PK\x05\x06"),                       // empty ZIP archives
+	[]byte("PK\x07\x08"),                       // spanned ZIP archives
+	[]byte("\x1f\x8b"),                         // gzip archives
+	[]byte("\xfd7zXZ\x00"),                     // xz archives
+	[]byte("7z\xbc\xaf\x27\x1c"),               // 7-Zip archives
+	[]byte("Rar!\x1a\x07"),                     // RAR archives
+	[]byte("\x28\xb5\x2f\xfd"),                 // Zstandard archives
+	[]byte("SQLite format 3\x00"),              // SQLite databases
+}
+
+// IsBinary checks if the content is from a binary file, inspecting the first DefaultBinarySniffSize bytes
+func IsBinary(content []byte) bool {
+	return IsBinaryWithSniffSize(content, DefaultBinarySniffSize)
+}
+
+// IsBinaryWithSniffSize checks if the content is from a binary file, inspecting only the first sniffSize bytes. A
+// content is considered binary when it starts with the magic number of a common binary format, or when the inspected
+// bytes contain a NUL byte or too many control bytes. Note that UTF-16 and UTF-32 texts are also considered binary,
+// since they contain NUL bytes. Empty contents are considered text
+func IsBinaryWithSniffSize(content []byte, sniffSize int) bool {
+	if hasMagicNumber(content) {
+		return true
+	}
+
+	sniffed := sniffContent(content, sniffSize)
+
+	return bytes.IndexByte(sniffed, 0) != -1 || hasTooManyControlBytes(sniffed)
+}
+
+// binaryFileKey is the key of the value that holds if an engine file is binary
+type binaryFileKey struct{}
+
+// IsBinary checks if the file is binary, see IsBinary function. The check is done only once and shared by all rules
+// analyzing the file. The engine checks it with the sniff size set by WithBinarySniffSize before running any rule,
+// so the rules see the same result, while files not loaded by the engine are checked with DefaultBinarySniffSize
+func (f *File) IsBinary() bool {
+	return f.isBinaryWithSniffSize(DefaultBinarySniffSize)
+}
+
+// isBinaryWithSniffSize checks if the file is binary inspecting only the first sniffSize bytes, unless it was
+// already checked before
+func (f *File) isBinaryWithSniffSize(sniffSize int) bool {
+	value, _ := f.Value(binaryFileKey{}, func() (interface{}, error) {
+		return IsBinaryWithSniffSize(f.Content, sniffSize), nil
+	})
+
+	return value.(bool)
+}
+
+// sniffContent returns the first sniffSize bytes of the content, or the whole content when sniffSize is zero
+func sniffContent(content []byte, sniffSize int) []byte {
+	if sniffSize > 0 && len(content) > sniffSize {
+		return content[:sniffSize]
+	}
+
+	return content
+}
+
+// hasTooManyControlBytes checks if the ratio of control bytes of the content is greater than the expected for texts
+func hasTooManyControlBytes(content []byte) bool {
+	return len(content) > 0 && float64(countControlBytes(content))/float64(len(content)) > maxControlBytesRatio
+}
+
+// hasMagicNumber checks if the content starts with the magic number of any common binary format
+func hasMagicNumber(content []byte) bool {
+	for _, magicNumber := range magicNumbers {
+		if bytes.HasPrefix(content, magicNumber) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// countControlBytes counts the bytes that are not expected in text files, which are the ASCII control characters
+// other than the ones used for whitespaces, backspace and escape sequences
+func countControlBytes(content []byte) (count int) {
+	for _, b := range content {
+		if isControlByte(b) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// isControlByte checks if the byte is an unexpected control character in text files
+func isControlByte(b byte) bool {
+	switch b {
+	case '\t', '\n', '\v', '\f', '\r', '\b', '\x1b':
+		return false
+	}
+
+	return b < ' ' || b == '\x7f'
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  []byte
+		expected bool
+	}{
+		{
+			name:     "Should return false for empty content",
+			content:  []byte{},
+			expected: false,
+		},
+		{
+			name:     "Should return false for content smaller than any magic number",
+			content:  []byte("a"),
+			expected: false,
+		},
+		{
+			name:     "Should return false for source code",
+			content:  []byte("package main\n\nfunc main() {\n\tprintln(\"\x1b[31mhello\x1b[0m\")\r\n}\n"),
+			expected: false,
+		},
+		{
+			name:     "Should return false for UTF-8 text",
+			content:  []byte("const senha = \"contraseña\" // 密码"),
+			expected: false,
+		},
+		{
+			name:     "Should return true for linux binaries",
+			content:  []byte("\x7fELF\x02\x01\x01"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for windows binaries",
+			content:  []byte("MZ\x90"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for java classes",
+			content:  []byte("\xca\xfe\xba\xbe"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for PNG images",
+			content:  []byte("\x89PNG\r\n\x1a\n"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for PDF documents",
+			content:  []byte("%PDF-1.7\n"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for ZIP archives",
+			content:  []byte("PK\x03\x04"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for content with NUL bytes",
+			content:  []byte("some\x00text"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for UTF-16 text",
+			content:  []byte("\xff\xfeh\x00i\x00"),
+			expected: true,
+		},
+		{
+			name:     "Should return true for content with many control bytes",
+			content:  []byte("\x01\x02\x03\x04text"),
+			expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, IsBinary(testCase.content))
+		})
+	}
+}
+
+func TestIsBinaryWithSniffSize(t *testing.T) {
+	content := []byte(strings.Repeat("a", 100) + "\x00")
+
+	t.Run("Should ignore NUL bytes after the sniff size", func(t *testing.T) {
+		assert.False(t, IsBinaryWithSniffSize(content, 100))
+	})
+
+	t.Run("Should find NUL bytes before the sniff size", func(t *testing.T) {
+		assert.True(t, IsBinaryWithSniffSize(content, 101))
+	})
+
+	t.Run("Should inspect the whole content when sniff size is zero", func(t *testing.T) {
+		assert.True(t, IsBinaryWithSniffSize(content, 0))
+	})
+}
+
+func TestEngineRunSkipsBinaryFiles(t *testing.T) {
+	projectPath := newTestProjectWithFiles(t, map[string]string{
+		"main.go":   "package main",
+		"image.png": "\x89PNG\r\n\x1a\n",
+		"small":     "a",
+		"empty":     "",
+		"app.class": "\xca\xfe\xba\xbe\x00\x00",
+	})
+
+	findings, err := NewEngine(1, AcceptAnyExtension).Run(context.Background(), projectPath,
+		newRuleMock([]Finding{{}}, nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, findings, 3)
+}
+
+func TestFileIsBinary(t *testing.T) {
+	content := []byte(strings.Repeat("a", 100) + "\x00")
+
+	t.Run("Should check the file with the default sniff size", func(t *testing.T) {
+		assert.True(t, NewFile("main.go", content).IsBinary())
+	})
+
+	t.Run("Should keep the result of the first check", func(t *testing.T) {
+		file := NewFile("main.go", content)
+
+		assert.False(t, file.isBinaryWithSniffSize(100))
+		assert.False(t, file.IsBinary())
+	})
+}
@@ -0,0 +1,356 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// LineRange is a range of lines of a file, both Start and End are 1-based and inclusive
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangedFile is a file changed in the project and the ranges of its added or modified lines
+type ChangedFile struct {
+	Path  string // Path is relative to the project path or absolute
+	Lines []LineRange
+}
+
+// RunFiles works like Run, but instead of walking the whole project it analyzes only the informed files, which can be
+// relative to the project path or absolute. The engine extensions and ignore patterns, as well as the .gitignore
+// files of the parent directories when WithGitIgnore is used, are still applied to the files. Files that don't
+// exist, like the ones removed by a change, files outside the project, directories and symbolic links are skipped
+func (e *Engine) RunFiles(ctx context.Context, projectPath string, files []string, rules ...Rule) ([]Finding, error) {
+	result, err := e.ScanFiles(ctx, projectPath, files, rules...)
+	if err != nil {
+		return result.Findings, err
+	}
+
+	return result.Findings, result.Err()
+}
+
+// ScanFiles works like Scan, but analyzes only the informed files, see RunFiles. When a baseline is informed through
+// WithBaseline, only the findings of the baseline from the informed files can be reported as fixed
+func (e *Engine) ScanFiles(ctx context.Context, projectPath string, files []string,
+	rules ...Rule) (*ScanResult, error) {
+	return e.scanFiles(ctx, projectPath, files, nil, rules)
+}
+
+// RunChanges works like RunFiles, analyzing the changed files, like the ones returned by GitChangedFiles. When the
+// WithChangedLinesOnly option is used, only the findings on the added or modified lines of each file are reported
+func (e *Engine) RunChanges(ctx context.Context, projectPath string, changes []ChangedFile,
+	rules ...Rule) ([]Finding, error) {
+	result, err := e.ScanChanges(ctx, projectPath, changes, rules...)
+	if err != nil {
+		return result.Findings, err
+	}
+
+	return result.Findings, result.Err()
+}
+
+// ScanChanges works like ScanFiles, analyzing the changed files, see RunChanges
+func (e *Engine) ScanChanges(ctx context.Context, projectPath string, changes []ChangedFile,
+	rules ...Rule) (*ScanResult, error) {
+	files := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		files = append(files, change.Path)
+	}
+
+	var lines map[string][]LineRange
+	if e.changedLinesOnly {
+		lines = changedLines(projectPath, changes)
+	}
+
+	return e.scanFiles(ctx, projectPath, files, lines, rules)
+}
+
+// changedLines returns the changed lines of each file inside the project by its name in the file system of the
+// project
+func changedLines(projectPath string, changes []ChangedFile) map[string][]LineRange {
+	lines := make(map[string][]LineRange, len(changes))
+
+	for _, change := range changes {
+		if name, ok := projectFileName(projectPath, change.Path); ok {
+			lines[name] = append(lines[name], change.Lines...)
+		}
+	}
+
+	return lines
+}
+
+// scanFiles analyzes the files of the project, reporting only the findings on the lines of each file when lines is
+// not nil. Files outside the project are skipped
+func (e *Engine) scanFiles(ctx context.Context, projectPath string, files []string, lines map[string][]LineRange,
+	rules []Rule) (*ScanResult, error) {
+	names := projectFileNames(projectPath, files)
+	target := &target{fsys: os.DirFS(projectPath), projectPath: projectPath, lines: lines}
+
+	target.listPaths = func(ctx context.Context) ([]string, error) {
+		return e.getValidFiles(ctx, target.fsys, names)
+	}
+
+	matcher := newBaselineMatcher(e.baseline)
+	matcher.onlyFiles(projectPath, names)
+
+	return e.scan(ctx, target, matcher, rules)
+}
+
+// projectFileNames converts the paths of the files into their names in the file system of the project, skipping the
+// files outside the project
+func projectFileNames(projectPath string, files []string) []string {
+	names := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if name, ok := projectFileName(projectPath, file); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// getValidFiles filters the names of the files that should be analyzed, applying the same validations and filters
+// used while walking the file system. The parent directories of each file are checked, so a file inside an ignored
+// directory is ignored too, and their .gitignore files are loaded only once. Duplicated names are analyzed only once
+func (e *Engine) getValidFiles(ctx context.Context, fsys fs.FS, names []string) ([]string, error) {
+	filter, err := e.newPathFilter(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFileValidator(e, fsys, filter).validNames(ctx, names)
+}
+
+// fileValidator validates the files informed to the engine, caching the entries of the directories already read and
+// the results of the parent directories already walked by the path filter
+type fileValidator struct {
+	engine     *Engine
+	fsys       fs.FS
+	filter     *pathFilter
+	walkedDirs map[string]error
+	dirEntries map[string][]fs.DirEntry
+	validated  map[string]bool
+}
+
+// newFileValidator creates a new fileValidator for the files of the file system
+func newFileValidator(engine *Engine, fsys fs.FS, filter *pathFilter) *fileValidator {
+	return &fileValidator{
+		engine:     engine,
+		fsys:       fsys,
+		filter:     filter,
+		walkedDirs: make(map[string]error),
+		dirEntries: make(map[string][]fs.DirEntry),
+		validated:  make(map[string]bool),
+	}
+}
+
+// validNames returns the names of the files that exist and should be analyzed
+func (v *fileValidator) validNames(ctx context.Context, names []string) ([]string, error) {
+	validNames := make([]string, 0, len(names))
+
+	for _, name := range names {
+		valid, err := v.isValid(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if valid {
+			validNames = append(validNames, name)
+		}
+	}
+
+	return validNames, nil
+}
+
+// isValid checks if the file exists and should be analyzed. Each name is validated only once, so it returns false
+// for the names already validated
+func (v *fileValidator) isValid(ctx context.Context, name string) (bool, error) {
+	if err := ctx.Err(); err != nil || v.validated[name] {
+		return false, err
+	}
+
+	v.validated[name] = true
+
+	entry, err := v.findDirEntry(name)
+	if entry == nil || err != nil {
+		return false, err
+	}
+
+	return v.isAnalyzed(name, entry)
+}
+
+// isAnalyzed checks if the file is not ignored, by itself or by its parent directories, and has a valid extension
+func (v *fileValidator) isAnalyzed(name string, entry fs.DirEntry) (bool, error) {
+	ignored, err := v.isParentDirIgnored(name)
+	if err != nil || ignored {
+		return false, err
+	}
+
+	return !v.engine.isInvalidFilePath(name, entry) && !v.filter.isIgnored(name, false), nil
+}
+
+// isParentDirIgnored walks the parent directories of the file, from the root to the nearest one, checking if any of
+// them is ignored and loading their .gitignore files
+func (v *fileValidator) isParentDirIgnored(name string) (bool, error) {
+	for _, dir := range parentDirs(name) {
+		err := v.walkDir(dir)
+		if errors.Is(err, fs.SkipDir) {
+			return true, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// walkDir walks the directory with the path filter, the result of each directory is cached, so each directory is
+// walked only once
+func (v *fileValidator) walkDir(dir string) error {
+	err, walked := v.walkedDirs[dir]
+	if !walked {
+		err = v.filter.walkDir(dir)
+		v.walkedDirs[dir] = err
+	}
+
+	return err
+}
+
+// findDirEntry returns the entry of the file in its parent directory, so symbolic links are not followed, like when
+// the file system is walked. It returns nil when the file doesn't exist
+func (v *fileValidator) findDirEntry(name string) (fs.DirEntry, error) {
+	entries, err := v.readDir(path.Dir(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return searchDirEntry(entries, path.Base(name)), nil
+}
+
+// readDir returns the entries of the directory, the entries of each directory are read only once
+func (v *fileValidator) readDir(dir string) ([]fs.DirEntry, error) {
+	if entries, ok := v.dirEntries[dir]; ok {
+		return entries, nil
+	}
+
+	entries, err := fs.ReadDir(v.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	v.dirEntries[dir] = entries
+
+	return entries, nil
+}
+
+// searchDirEntry returns the entry with the base name from the entries sorted by name, or nil when there is none
+func searchDirEntry(entries []fs.DirEntry, base string) fs.DirEntry {
+	index := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Name() >= base
+	})
+
+	if index == len(entries) || entries[index].Name() != base {
+		return nil
+	}
+
+	return entries[index]
+}
+
+// projectFileName converts the path of a file, relative to the project path or absolute, into its name in the file
+// system of the project. It returns false when the file is outside the project
+func projectFileName(projectPath, file string) (string, bool) {
+	relativePath, err := relativeProjectPath(projectPath, file)
+	if err != nil {
+		return "", false
+	}
+
+	name := filepath.ToSlash(relativePath)
+
+	return name, fs.ValidPath(name)
+}
+
+// relativeProjectPath returns the path of the file relative to the project path, relative paths are only cleaned
+func relativeProjectPath(projectPath, file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		return filepath.Clean(file), nil
+	}
+
+	absProjectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Rel(absProjectPath, file)
+}
+
+// filterChangedLines returns a findings handler that removes the findings that are not on the changed lines before
+// calling next. Findings without a line, which refer to the whole file, are always kept
+func filterChangedLines(lines []LineRange, next findingsHandler) findingsHandler {
+	return func(ctx context.Context, findings []Finding) error {
+		filtered := make([]Finding, 0, len(findings))
+
+		for index := range findings {
+			if isOnChangedLines(&findings[index].SourceLocation, lines) {
+				filtered = append(filtered, findings[index])
+			}
+		}
+
+		return handleIfAny(ctx, next, filtered)
+	}
+}
+
+// isOnChangedLines checks if any line of the location is in the line ranges, file level findings are always kept
+func isOnChangedLines(location *Location, lines []LineRange) bool {
+	if location.FileLevel || location.Line <= 0 {
+		return true
+	}
+
+	for _, lineRange := range lines {
+		if lineRange.overlaps(location.Line, endLineOf(location)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// endLineOf returns the last line of the location, which is its first line when the end line is not set
+func endLineOf(location *Location) int {
+	if location.EndLine < location.Line {
+		return location.Line
+	}
+
+	return location.EndLine
+}
+
+// overlaps checks if any line from start to end, both inclusive, is in the range
+func (r LineRange) overlaps(start, end int) bool {
+	return start <= r.End && end >= r.Start
+}
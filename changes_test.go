@@ -0,0 +1,220 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linesRuleMock returns one finding for each one of the lines in each file analyzed
+type linesRuleMock struct {
+	Metadata
+	lines []int
+}
+
+func (r *linesRuleMock) Run(path string) ([]Finding, error) {
+	return r.RunFile(NewFile(path, nil))
+}
+
+func (r *linesRuleMock) RunFile(file *File) ([]Finding, error) {
+	findings := make([]Finding, 0, len(r.lines))
+
+	for _, line := range r.lines {
+		findings = append(findings, Finding{ID: r.ID, SourceLocation: Location{Filename: file.Path, Line: line}})
+	}
+
+	return findings, nil
+}
+
+// findingsLocations returns the relative path and line of each finding, sorted
+func findingsLocations(t *testing.T, projectPath string, findings []Finding) []string {
+	locations := make([]string, 0, len(findings))
+
+	for index := range findings {
+		relativePath, err := filepath.Rel(projectPath, findings[index].SourceLocation.Filename)
+		require.NoError(t, err)
+
+		locations = append(locations, fmt.Sprintf("%s:%d", filepath.ToSlash(relativePath),
+			findings[index].SourceLocation.Line))
+	}
+
+	sort.Strings(locations)
+
+	return locations
+}
+
+func TestEngineRunFiles(t *testing.T) {
+	files := map[string]string{
+		".gitignore":        "dist/\n",
+		"main.go":           "",
+		"main_test.go":      "",
+		"internal/a.go":     "",
+		"internal/b.go":     "",
+		"dist/generated.go": "",
+		"README.md":         "",
+	}
+
+	testCases := []struct {
+		name          string
+		options       []Option
+		files         []string
+		expectedPaths []string
+	}{
+		{
+			name:          "Should analyze only the informed files",
+			files:         []string{"main.go", filepath.Join("internal", "b.go")},
+			expectedPaths: []string{"internal/b.go", "main.go"},
+		},
+		{
+			name:          "Should skip files that don't exist, directories and duplicated files",
+			files:         []string{"main.go", "removed.go", "internal", "./main.go"},
+			expectedPaths: []string{"main.go"},
+		},
+		{
+			name:          "Should apply the extensions and ignore patterns",
+			options:       []Option{WithIgnorePatterns("**/*_test.go")},
+			files:         []string{"main.go", "main_test.go", "README.md"},
+			expectedPaths: []string{"main.go"},
+		},
+		{
+			name:          "Should honor the .gitignore files of the parent directories",
+			options:       []Option{WithGitIgnore()},
+			files:         []string{"dist/generated.go", "internal/a.go"},
+			expectedPaths: []string{"internal/a.go"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			projectPath := newTestProjectWithFiles(t, files)
+			engine := NewEngineWithOptions(1, []string{".go"}, testCase.options...)
+			rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}}
+
+			findings, err := engine.RunFiles(context.Background(), projectPath, testCase.files, rule)
+			require.NoError(t, err)
+
+			paths := make([]string, 0, len(findings))
+			for index := range findings {
+				paths = append(paths, findings[index].SourceLocation.Filename)
+			}
+
+			assert.Equal(t, testCase.expectedPaths, relativePaths(t, projectPath, paths))
+		})
+	}
+
+	t.Run("Should accept absolute paths", func(t *testing.T) {
+		projectPath := newTestProjectWithFiles(t, files)
+		rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+		findings, err := NewEngine(1, ".go").
+			RunFiles(context.Background(), projectPath, []string{filepath.Join(projectPath, "main.go")}, rule)
+		require.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, Fingerprint("HS-TEST-1", "main.go", "call(a)"), findings[0].Fingerprint)
+		}
+	})
+}
+
+func TestEngineScanFilesWithBaseline(t *testing.T) {
+	t.Run("Should report as fixed only the baseline findings of the informed files", func(t *testing.T) {
+		projectPath := newTestProject(t, 2)
+		rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+		baseline := NewBaseline([]Finding{
+			newBaselineTestFinding("HS-TEST-2", filepath.Join(projectPath, "file0.go"), 1, "call(b)"),
+			newBaselineTestFinding("HS-TEST-2", filepath.Join(projectPath, "file1.go"), 1, "call(b)"),
+		})
+
+		result, err := NewEngineWithOptions(1, []string{".go"}, WithBaseline(baseline)).
+			ScanFiles(context.Background(), projectPath, []string{"file0.go"}, rule)
+		require.NoError(t, err)
+
+		assert.Len(t, result.Findings, 1)
+		assert.Equal(t, baseline.Findings[:1], result.Fixed)
+	})
+
+	t.Run("Should not report the baseline findings outside the changed lines as fixed", func(t *testing.T) {
+		projectPath := newTestProject(t, 1)
+		rule := &linesRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, lines: []int{2, 5}}
+		changes := []ChangedFile{{Path: "file0.go", Lines: []LineRange{{Start: 5, End: 5}}}}
+
+		previous, err := NewEngine(1, ".go").Run(context.Background(), projectPath, rule)
+		require.NoError(t, err)
+
+		result, err := NewEngineWithOptions(1, []string{".go"}, WithBaseline(NewBaseline(previous)),
+			WithChangedLinesOnly()).ScanChanges(context.Background(), projectPath, changes, rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"file0.go:5"}, findingsLocations(t, projectPath, result.Findings))
+		assert.Equal(t, BaselineUnchanged, result.Findings[0].BaselineState)
+		assert.Empty(t, result.Fixed)
+	})
+}
+
+func TestEngineRunChanges(t *testing.T) {
+	projectPath := newTestProject(t, 2)
+	rule := &linesRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, lines: []int{0, 2, 5, 8}}
+	changes := []ChangedFile{
+		{Path: "file0.go", Lines: []LineRange{{Start: 1, End: 2}, {Start: 7, End: 9}}},
+		{Path: "file1.go"},
+	}
+
+	t.Run("Should report only the findings on the changed lines with WithChangedLinesOnly", func(t *testing.T) {
+		findings, err := NewEngineWithOptions(1, []string{".go"}, WithChangedLinesOnly()).
+			RunChanges(context.Background(), projectPath, changes, rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"file0.go:0", "file0.go:2", "file0.go:8", "file1.go:0"},
+			findingsLocations(t, projectPath, findings))
+	})
+
+	t.Run("Should report all findings of the changed files without WithChangedLinesOnly", func(t *testing.T) {
+		findings, err := NewEngine(1, ".go").RunChanges(context.Background(), projectPath, changes[:1], rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"file0.go:0", "file0.go:2", "file0.go:5", "file0.go:8"},
+			findingsLocations(t, projectPath, findings))
+	})
+}
+
+func TestIsOnChangedLines(t *testing.T) {
+	lines := []LineRange{{Start: 3, End: 4}, {Start: 10, End: 10}}
+
+	testCases := []struct {
+		name     string
+		location Location
+		expected bool
+	}{
+		{name: "Should accept a line inside a range", location: Location{Line: 4}, expected: true},
+		{name: "Should reject a line outside the ranges", location: Location{Line: 5}, expected: false},
+		{name: "Should accept a location ending inside a range", location: Location{Line: 1, EndLine: 3}, expected: true},
+		{name: "Should accept a location containing a range", location: Location{Line: 8, EndLine: 12}, expected: true},
+		{name: "Should accept a location without line", location: Location{}, expected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, isOnChangedLines(&testCase.location, lines))
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command horusec-engine analyzes a project with the text rules declared in YAML or JSON files, writing the findings
+// as text, JSON or SARIF. The exit code is 0 when nothing was found, 1 when there are findings with the severity
+// threshold or above, and 2 when the analysis failed. Run it with -h to see all flags
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/severities"
+
+	engine "github.com/ZupIT/horusec-engine"
+	"github.com/ZupIT/horusec-engine/text"
+)
+
+// Exit codes of the command
+const (
+	ExitCodeOK       = 0
+	ExitCodeFindings = 1
+	ExitCodeError    = 2
+)
+
+// Output formats of the findings
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// defaultPoolSize is the default number of goroutines analyzing the files
+const defaultPoolSize = 10
+
+// config holds the flags of the command
+type config struct {
+	rules      listFlag
+	path       string
+	extensions listFlag
+	poolSize   int
+	ignore     listFlag
+	gitIgnore  bool
+	severity   string
+	format     string
+	output     string
+}
+
+// listFlag is a flag that can be repeated, and each value can have many items separated by commas
+type listFlag []string
+
+// String returns the items separated by commas
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set adds the items of the value, separated by commas, to the list
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	exitCode := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(exitCode)
+}
+
+// run executes the command with the arguments, writing the findings into stdout, unless an output file is informed,
+// and the errors into stderr. It returns the exit code of the command
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitCodeOK
+	}
+
+	if err != nil {
+		return printError(stderr, err)
+	}
+
+	rep, err := scanAndWrite(ctx, cfg, stdout)
+	if err != nil {
+		return printError(stderr, err)
+	}
+
+	return rep.exitCode(stderr)
+}
+
+// printError writes the error into stderr and returns the exit code of errors
+func printError(stderr io.Writer, err error) int {
+	_, _ = fmt.Fprintln(stderr, err)
+
+	return ExitCodeError
+}
+
+// parseFlags parses and validates the flags of the command
+// nolint:funlen // the flags are declared here, breaking this function will lead to a more complex code
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	cfg := &config{}
+
+	flags := flag.NewFlagSet("horusec-engine", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&cfg.rules, "rules", "YAML or JSON rule files or directories, separated by commas or repeated (required)")
+	flags.StringVar(&cfg.path, "path", ".", "path of the project to analyze")
+	flags.Var(&cfg.extensions, "ext", "extensions of the files to analyze, like .java,.kt (default any extension)")
+	flags.IntVar(&cfg.poolSize, "pool", defaultPoolSize, "number of goroutines analyzing the files")
+	flags.Var(&cfg.ignore, "ignore", "doublestar glob patterns of files and directories to ignore, like **/test/**")
+	flags.BoolVar(&cfg.gitIgnore, "gitignore", false, "ignore the files ignored by the .gitignore files")
+	flags.StringVar(&cfg.severity, "severity", severities.Info.ToString(),
+		"minimum severity of the reported findings: CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN or INFO")
+	flags.StringVar(&cfg.format, "format", FormatText, "output format: text, json or sarif")
+	flags.StringVar(&cfg.output, "output", "", "file to write the findings (default stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(flags.NArg()); err != nil {
+		return nil, err
+	}
+
+	cfg.setDefaults()
+
+	return cfg, nil
+}
+
+// validate checks the values of the flags, args is the number of arguments that are not flags
+func (c *config) validate(args int) error {
+	switch {
+	case args > 0:
+		return errors.New("unexpected arguments, use -path to inform the project path")
+	case len(c.rules) == 0:
+		return errors.New("at least one rules file or directory must be informed with -rules")
+	case c.poolSize <= 0:
+		return fmt.Errorf("invalid pool size %d, it must be greater than zero", c.poolSize)
+	}
+
+	return c.validateOutput()
+}
+
+// validateOutput checks the flags of the reported findings and of their output format
+func (c *config) validateOutput() error {
+	switch {
+	case !severities.Contains(c.severity):
+		return fmt.Errorf("invalid severity %q", c.severity)
+	case c.format != FormatText && c.format != FormatJSON && c.format != FormatSARIF:
+		return fmt.Errorf("invalid format %q, it must be text, json or sarif", c.format)
+	}
+
+	return nil
+}
+
+// setDefaults normalizes the severity and the extensions, adding their leading dot like the extensions of the rules
+// files, and sets the extensions that aren't informed to accept any extension
+func (c *config) setDefaults() {
+	c.severity = strings.ToUpper(c.severity)
+
+	if len(c.extensions) == 0 {
+		c.extensions = listFlag{engine.AcceptAnyExtension}
+	}
+
+	for index, extension := range c.extensions {
+		c.extensions[index] = addLeadingDot(extension)
+	}
+}
+
+// addLeadingDot adds the leading dot of the extension when it doesn't have it, like "java"
+func addLeadingDot(extension string) string {
+	if extension == engine.AcceptAnyExtension || strings.HasPrefix(extension, ".") {
+		return extension
+	}
+
+	return "." + extension
+}
+
+// report is the result of the analysis that is written by the command
+type report struct {
+	findings []engine.Finding  // findings holds the findings with the severity threshold or above
+	rules    []engine.Metadata // rules holds the metadata of the rules that ran
+	errs     error             // errs holds the errors of the files and rules that didn't stop the analysis
+}
+
+// scan loads the rules and analyzes the project, the returned error is set when the analysis couldn't finish
+func scan(ctx context.Context, cfg *config) (*report, error) {
+	rules, err := text.LoadRules(cfg.rules...)
+	if err != nil {
+		return nil, err
+	}
+
+	eng := engine.NewEngineWithOptions(cfg.poolSize, cfg.extensions, cfg.engineOptions()...)
+
+	result, err := eng.Scan(ctx, cfg.path, rules...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReport(result, rules, cfg.severity), nil
+}
+
+// newReport creates the report of the scan result, with the findings with the severity threshold or above
+func newReport(result *engine.ScanResult, rules []engine.Rule, severity string) *report {
+	return &report{
+		findings: filterBySeverity(result.Findings, severities.GetSeverityByString(severity)),
+		rules:    getMetadata(rules),
+		errs:     result.Err(),
+	}
+}
+
+// scanAndWrite analyzes the project and writes the report into the output
+func scanAndWrite(ctx context.Context, cfg *config, stdout io.Writer) (*report, error) {
+	rep, err := scan(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return rep, writeOutput(cfg, stdout, rep)
+}
+
+// engineOptions returns the options of the engine according to the flags. Suppressed findings are never reported
+func (c *config) engineOptions() []engine.Option {
+	options := []engine.Option{
+		engine.WithIgnorePatterns(c.ignore...),
+		engine.WithErrorPolicy(engine.ContinueOnError),
+		engine.WithoutSuppressedFindings(),
+	}
+
+	if c.gitIgnore {
+		options = append(options, engine.WithGitIgnore())
+	}
+
+	return options
+}
+
+// getMetadata returns the metadata of the rules
+func getMetadata(rules []engine.Rule) []engine.Metadata {
+	metadata := make([]engine.Metadata, 0, len(rules))
+
+	for _, rule := range rules {
+		if provider, ok := rule.(engine.MetadataProvider); ok {
+			metadata = append(metadata, provider.GetMetadata())
+		}
+	}
+
+	return metadata
+}
+
+// writeOutput writes the report in the output format into the output file, or into stdout when it's not informed
+func writeOutput(cfg *config, stdout io.Writer, rep *report) error {
+	if cfg.output == "" {
+		return writeFindings(stdout, cfg.format, rep.findings, rep.rules)
+	}
+
+	return writeOutputFile(cfg.output, cfg.format, rep)
+}
+
+// writeOutputFile creates the file with the name and writes the report in the format into it
+func writeOutputFile(name, format string, rep *report) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err = writeFindings(file, format, rep.findings, rep.rules); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// exitCode returns the exit code according to the findings and the errors of the analysis, which are written into
+// stderr. Errors take precedence over findings, since the analysis may have missed some of them
+func (r *report) exitCode(stderr io.Writer) int {
+	if r.errs != nil {
+		_, _ = fmt.Fprintln(stderr, r.errs)
+
+		return ExitCodeError
+	}
+
+	if len(r.findings) > 0 {
+		return ExitCodeFindings
+	}
+
+	return ExitCodeOK
+}
@@ -0,0 +1,235 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+	"github.com/ZupIT/horusec-engine/report/sarif"
+)
+
+const testRules = `rules:
+  - id: HS-TEST-1
+    name: Weak hash
+    description: MD5 is a weak hash algorithm
+    severity: MEDIUM
+    confidence: HIGH
+    cwes: [CWE-327]
+    expressions:
+      - MessageDigest\.getInstance\("MD5"\)
+    extensions: [.java]
+  - id: HS-TEST-2
+    name: Debug log
+    description: Debug logs should not be used
+    severity: LOW
+    confidence: LOW
+    expressions:
+      - Log\.d\(
+`
+
+// newTestProject creates a temporary project with the files and a rules file, returning their paths
+func newTestProject(t *testing.T, files map[string]string) (projectPath, rulesPath string) {
+	projectPath = t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(projectPath, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	rulesPath = filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(testRules), 0o600))
+
+	return projectPath, rulesPath
+}
+
+// runCommand runs the command with the arguments, returning the exit code, stdout and stderr
+func runCommand(args ...string) (int, string, string) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	exitCode := run(context.Background(), args, stdout, stderr)
+
+	return exitCode, stdout.String(), stderr.String()
+}
+
+// nolint:funlen // table of command arguments
+func TestRun(t *testing.T) {
+	projectPath, rulesPath := newTestProject(t, map[string]string{
+		"src/A.java":      "class A {\n  MessageDigest.getInstance(\"MD5\");\n  Log.d(\"a\");\n}\n",
+		"src/B.kt":        "fun b() {\n  Log.d(\"b\") // horusec-ignore\n}\n",
+		"test/ATest.java": "class ATest {\n  MessageDigest.getInstance(\"MD5\");\n}\n",
+	})
+
+	testCases := []struct {
+		name             string
+		args             []string
+		expectedExitCode int
+		expectedOutput   string
+	}{
+		{
+			name:             "Should report the findings as text and exit with 1",
+			args:             []string{"-rules", rulesPath, "-path", projectPath, "-ignore", "test/**"},
+			expectedExitCode: ExitCodeFindings,
+			expectedOutput: filepath.Join(projectPath, "src", "A.java") + ":2:3: MEDIUM HS-TEST-1: Weak hash\n" +
+				"    MessageDigest.getInstance(\"MD5\");\n" +
+				filepath.Join(projectPath, "src", "A.java") + ":3:3: LOW HS-TEST-2: Debug log\n" +
+				"    Log.d(\"a\");\n" +
+				"2 finding(s)\n",
+		},
+		{
+			name:             "Should report only the findings with the severity threshold or above",
+			args:             []string{"-rules", rulesPath, "-path", projectPath, "-severity", "medium"},
+			expectedExitCode: ExitCodeFindings,
+			expectedOutput: filepath.Join(projectPath, "src", "A.java") + ":2:3: MEDIUM HS-TEST-1: Weak hash\n" +
+				"    MessageDigest.getInstance(\"MD5\");\n" +
+				filepath.Join(projectPath, "test", "ATest.java") + ":2:3: MEDIUM HS-TEST-1: Weak hash\n" +
+				"    MessageDigest.getInstance(\"MD5\");\n" +
+				"2 finding(s)\n",
+		},
+		{
+			name:             "Should exit with 0 when nothing is found",
+			args:             []string{"-rules", rulesPath, "-path", projectPath, "-ext", ".kt"},
+			expectedExitCode: ExitCodeOK,
+			expectedOutput:   "0 finding(s)\n",
+		},
+		{
+			name: "Should add the leading dot of the extensions",
+			args: []string{
+				"-rules", rulesPath, "-path", projectPath, "-ext", "java", "-ignore", "test/**", "-severity", "medium",
+			},
+			expectedExitCode: ExitCodeFindings,
+			expectedOutput: filepath.Join(projectPath, "src", "A.java") + ":2:3: MEDIUM HS-TEST-1: Weak hash\n" +
+				"    MessageDigest.getInstance(\"MD5\");\n" +
+				"1 finding(s)\n",
+		},
+		{
+			name:             "Should exit with 2 when the rules are not informed",
+			args:             []string{"-path", projectPath},
+			expectedExitCode: ExitCodeError,
+		},
+		{
+			name:             "Should exit with 2 when the rules file doesn't exist",
+			args:             []string{"-rules", filepath.Join(projectPath, "missing.yaml"), "-path", projectPath},
+			expectedExitCode: ExitCodeError,
+		},
+		{
+			name:             "Should exit with 2 when the project doesn't exist",
+			args:             []string{"-rules", rulesPath, "-path", filepath.Join(projectPath, "missing")},
+			expectedExitCode: ExitCodeError,
+		},
+		{
+			name:             "Should exit with 2 when a flag is invalid",
+			args:             []string{"-rules", rulesPath, "-format", "xml"},
+			expectedExitCode: ExitCodeError,
+		},
+		{
+			name:             "Should exit with 2 when the severity is invalid",
+			args:             []string{"-rules", rulesPath, "-severity", "urgent"},
+			expectedExitCode: ExitCodeError,
+		},
+		{
+			name:             "Should exit with 0 when the help is requested",
+			args:             []string{"-h"},
+			expectedExitCode: ExitCodeOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			exitCode, stdout, stderr := runCommand(testCase.args...)
+
+			assert.Equal(t, testCase.expectedExitCode, exitCode, stderr)
+			assert.Equal(t, testCase.expectedOutput, stdout)
+
+			if testCase.expectedExitCode == ExitCodeError {
+				assert.NotEmpty(t, stderr)
+			}
+		})
+	}
+}
+
+func TestRunOutputFormats(t *testing.T) {
+	projectPath, rulesPath := newTestProject(t, map[string]string{
+		"A.java": "class A {\n  MessageDigest.getInstance(\"MD5\");\n}\n",
+	})
+
+	t.Run("Should write the findings as JSON", func(t *testing.T) {
+		exitCode, stdout, _ := runCommand("-rules", rulesPath, "-path", projectPath, "-format", "json")
+		require.Equal(t, ExitCodeFindings, exitCode)
+
+		var findings []jsonFinding
+		require.NoError(t, json.Unmarshal([]byte(stdout), &findings))
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, "HS-TEST-1", findings[0].RuleID)
+			assert.Equal(t, []string{"CWE-327"}, findings[0].CWEs)
+			assert.Equal(t, 2, findings[0].Line)
+			assert.NotEmpty(t, findings[0].Fingerprint)
+		}
+	})
+
+	t.Run("Should write the findings as SARIF into the output file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "result.sarif")
+
+		exitCode, stdout, _ := runCommand("-rules", rulesPath, "-path", projectPath, "-format", "sarif",
+			"-output", output)
+		require.Equal(t, ExitCodeFindings, exitCode)
+		assert.Empty(t, stdout)
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+
+		var log sarif.Log
+		require.NoError(t, json.Unmarshal(content, &log))
+
+		if assert.Len(t, log.Runs, 1) {
+			assert.Len(t, log.Runs[0].Tool.Driver.Rules, 2)
+			assert.Len(t, log.Runs[0].Results, 1)
+		}
+	})
+}
+
+func TestWriteText(t *testing.T) {
+	t.Run("Should write only the file name as location of file level findings", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		findings := []engine.Finding{{
+			ID: "HS-TEST-3", Name: "Missing setting", Severity: "LOW",
+			SourceLocation: engine.Location{Filename: "pom.xml", FileLevel: true},
+		}}
+
+		require.NoError(t, writeText(output, findings))
+		assert.Equal(t, "pom.xml: LOW HS-TEST-3: Missing setting\n1 finding(s)\n", output.String())
+	})
+}
+
+func TestListFlag(t *testing.T) {
+	t.Run("Should accept repeated values separated by commas", func(t *testing.T) {
+		var list listFlag
+
+		assert.NoError(t, list.Set(".java, .kt"))
+		assert.NoError(t, list.Set(".go,"))
+		assert.Equal(t, listFlag{".java", ".kt", ".go"}, list)
+		assert.Equal(t, ".java,.kt,.go", list.String())
+	})
+}
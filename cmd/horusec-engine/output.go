@@ -0,0 +1,184 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/severities"
+
+	engine "github.com/ZupIT/horusec-engine"
+	"github.com/ZupIT/horusec-engine/report/sarif"
+)
+
+// jsonFinding is the representation of a finding in the JSON output
+type jsonFinding struct {
+	RuleID      string   `json:"ruleId"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    string   `json:"severity"`
+	Confidence  string   `json:"confidence"`
+	CWEs        []string `json:"cwes,omitempty"`
+	Filename    string   `json:"filename"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	EndLine     int      `json:"endLine,omitempty"`
+	EndColumn   int      `json:"endColumn,omitempty"`
+	FileLevel   bool     `json:"fileLevel,omitempty"`
+	CodeSample  string   `json:"codeSample"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+// severityRank returns the rank of the severity, lower ranks are more severe. The severities are ranked in the order
+// of severities.Values, and invalid values are converted into severities.Unknown
+func severityRank(severity string) int {
+	value := severities.GetSeverityByString(strings.ToUpper(severity))
+	values := severities.Values()
+
+	for rank, current := range values {
+		if current == value {
+			return rank
+		}
+	}
+
+	return len(values)
+}
+
+// filterBySeverity returns the findings with the threshold severity or a more severe one, sorted by their location
+func filterBySeverity(findings []engine.Finding, threshold severities.Severity) []engine.Finding {
+	thresholdRank := severityRank(threshold.ToString())
+	filtered := make([]engine.Finding, 0, len(findings))
+
+	for index := range findings {
+		if severityRank(findings[index].Severity) <= thresholdRank {
+			filtered = append(filtered, findings[index])
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return lessFinding(&filtered[i], &filtered[j])
+	})
+
+	return filtered
+}
+
+// lessFinding sorts the findings by file, line, column and rule ID, so the output is always the same
+func lessFinding(first, second *engine.Finding) bool {
+	if first.SourceLocation.Filename != second.SourceLocation.Filename {
+		return first.SourceLocation.Filename < second.SourceLocation.Filename
+	}
+
+	if first.SourceLocation.Line != second.SourceLocation.Line {
+		return first.SourceLocation.Line < second.SourceLocation.Line
+	}
+
+	if first.SourceLocation.Column != second.SourceLocation.Column {
+		return first.SourceLocation.Column < second.SourceLocation.Column
+	}
+
+	return first.ID < second.ID
+}
+
+// writeFindings writes the findings in the format, the metadata of the rules is only used by the SARIF format
+func writeFindings(w io.Writer, format string, findings []engine.Finding, rules []engine.Metadata) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, findings)
+	case FormatSARIF:
+		return sarif.Write(w, findings, rules)
+	default:
+		return writeText(w, findings)
+	}
+}
+
+// writeJSON writes the findings as an indented JSON array
+func writeJSON(w io.Writer, findings []engine.Finding) error {
+	output := make([]jsonFinding, 0, len(findings))
+
+	for index := range findings {
+		output = append(output, newJSONFinding(&findings[index]))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(output)
+}
+
+// newJSONFinding creates the finding of the JSON output
+func newJSONFinding(finding *engine.Finding) jsonFinding {
+	location := &finding.SourceLocation
+
+	return jsonFinding{
+		RuleID:      finding.ID,
+		Name:        finding.Name,
+		Description: finding.Description,
+		Severity:    finding.Severity,
+		Confidence:  finding.Confidence,
+		CWEs:        getCWEs(finding),
+		Filename:    location.Filename, Line: location.Line, Column: location.Column,
+		EndLine: location.EndLine, EndColumn: location.EndColumn, FileLevel: location.FileLevel,
+		CodeSample:  finding.CodeSample,
+		Fingerprint: finding.Fingerprint,
+	}
+}
+
+// getCWEs returns the CWEs of the rule that reported the finding, if its metadata is known
+func getCWEs(finding *engine.Finding) []string {
+	if finding.Metadata == nil {
+		return nil
+	}
+
+	return finding.Metadata.CWEs
+}
+
+// writeText writes each finding in a line with its location, severity, rule and name, followed by the code sample,
+// and a summary with the number of findings at the end. The location of file level findings is only the file name
+func writeText(w io.Writer, findings []engine.Finding) error {
+	writer := bufio.NewWriter(w)
+
+	for index := range findings {
+		writeTextFinding(writer, &findings[index])
+	}
+
+	_, _ = fmt.Fprintf(writer, "%d finding(s)\n", len(findings))
+
+	return writer.Flush()
+}
+
+// writeTextFinding writes the line of the finding, followed by its code sample when it has one
+func writeTextFinding(w io.Writer, finding *engine.Finding) {
+	_, _ = fmt.Fprintf(w, "%s: %s %s: %s\n", textLocation(&finding.SourceLocation), finding.Severity,
+		finding.ID, finding.Name)
+
+	if finding.CodeSample != "" {
+		_, _ = fmt.Fprintf(w, "    %s\n", finding.CodeSample)
+	}
+}
+
+// textLocation returns the location of the text output, like "path:line:column", or only the path of file level
+// findings
+func textLocation(location *engine.Location) string {
+	if location.FileLevel {
+		return location.Filename
+	}
+
+	return fmt.Sprintf("%s:%d:%d", location.Filename, location.Line, location.Column)
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import "context"
+
+// RunContent runs the rules directly on the content of a file already in memory, like an unsaved editor buffer or a
+// git blob, without reading it from disk. The filename is used to filter the file by the engine extensions and
+// ignore patterns and to report the findings, it should be relative to the project, like "src/main.go", so the
+// fingerprints of the findings match the ones of a project analysis. The .gitignore files are not applied, and
+// errors are handled according to the engine error policy, like in Run
+func (e *Engine) RunContent(ctx context.Context, filename string, content []byte, rules ...Rule) ([]Finding, error) {
+	result, err := e.ScanContent(ctx, filename, content, rules...)
+	if err != nil {
+		return result.Findings, err
+	}
+
+	return result.Findings, result.Err()
+}
+
+// ScanContent works like RunContent, but returns a structured result like Scan. When a baseline is informed through
+// WithBaseline, only the findings of the baseline from the same file can be reported as fixed
+func (e *Engine) ScanContent(ctx context.Context, filename string, content []byte,
+	rules ...Rule) (*ScanResult, error) {
+	ignored, err := e.isContentIgnored(filename)
+	if err != nil || ignored {
+		return new(ScanResult), err
+	}
+
+	return e.scanContent(ctx, NewFile(filename, content), rules)
+}
+
+// scanContent runs the rules over the file, only the baseline findings of the same file can be reported as fixed
+func (e *Engine) scanContent(ctx context.Context, file *File, rules []Rule) (*ScanResult, error) {
+	result := new(ScanResult)
+	matcher := newBaselineMatcher(e.baseline)
+	matcher.onlyFiles("", []string{file.Path})
+	collector := newErrorCollector(e.errorPolicy)
+
+	err := e.runRules(ctx, rules, file, e.prepareFindings("", matcher, collectFindings(&result.Findings)),
+		collector.handle)
+
+	result.Errors = collector.errors
+
+	if err == nil {
+		result.Fixed = matcher.fixed(result.Errors)
+	}
+
+	return result, err
+}
+
+// isContentIgnored checks if the content with the file name should not be analyzed, according to the engine
+// extensions and ignore and include patterns
+func (e *Engine) isContentIgnored(filename string) (bool, error) {
+	name := normalizePath(filename)
+
+	if e.isInvalidExtension(name) || e.isFileFromGitFolder(name) {
+		return true, nil
+	}
+
+	filter, err := e.newPathFilter(nil)
+	if err != nil {
+		return false, err
+	}
+
+	return filter.isIgnored(name, false), nil
+}
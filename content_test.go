@@ -0,0 +1,114 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineRunContent(t *testing.T) {
+	rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+	testCases := []struct {
+		name             string
+		options          []Option
+		filename         string
+		content          string
+		expectedFindings int
+	}{
+		{
+			name:             "Should run the rules on the content",
+			filename:         "src/main.go",
+			content:          "package main",
+			expectedFindings: 1,
+		},
+		{
+			name:     "Should ignore content with extensions that are not analyzed",
+			filename: "README.md",
+			content:  "# README",
+		},
+		{
+			name:     "Should ignore content matching the ignore patterns",
+			options:  []Option{WithIgnorePatterns("**/*_test.go")},
+			filename: "src/main_test.go",
+			content:  "package main",
+		},
+		{
+			name:     "Should ignore binary content",
+			filename: "src/main.go",
+			content:  "\x7fELF\x02\x01\x00\x00",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			findings, err := NewEngineWithOptions(1, []string{".go"}, testCase.options...).
+				RunContent(context.Background(), testCase.filename, []byte(testCase.content), rule)
+
+			require.NoError(t, err)
+			assert.Len(t, findings, testCase.expectedFindings)
+		})
+	}
+
+	t.Run("Should report the findings with the file name and its fingerprint", func(t *testing.T) {
+		findings, err := NewEngine(1, ".go").RunContent(context.Background(), "src/main.go", []byte("package main"), rule)
+		require.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, "src/main.go", findings[0].SourceLocation.Filename)
+			assert.Equal(t, Fingerprint("HS-TEST-1", "src/main.go", "call(a)"), findings[0].Fingerprint)
+		}
+	})
+
+	t.Run("Should return the errors of the rules", func(t *testing.T) {
+		result, err := NewEngineWithOptions(1, []string{".go"}, WithErrorPolicy(ContinueOnError)).
+			ScanContent(context.Background(), "main.go", []byte("package main"), newRuleMock(nil, errors.New("test")), rule)
+		require.NoError(t, err)
+
+		assert.Len(t, result.Findings, 1)
+		assert.Len(t, result.Errors, 1)
+	})
+
+	t.Run("Should return the context error when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewEngine(1, ".go").RunContent(ctx, "main.go", []byte("package main"), rule)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Should report as fixed only the baseline findings of the file", func(t *testing.T) {
+		baseline := NewBaseline([]Finding{
+			newBaselineTestFinding("HS-TEST-1", "src/main.go", 1, "call(a)"),
+			newBaselineTestFinding("HS-TEST-2", "src/main.go", 2, "call(b)"),
+			newBaselineTestFinding("HS-TEST-2", "src/other.go", 2, "call(b)"),
+		})
+
+		result, err := NewEngineWithOptions(1, []string{".go"}, WithBaseline(baseline)).
+			ScanContent(context.Background(), "src/main.go", []byte("package main"), rule)
+		require.NoError(t, err)
+
+		if assert.Len(t, result.Findings, 1) {
+			assert.Equal(t, BaselineUnchanged, result.Findings[0].BaselineState)
+		}
+
+		assert.Equal(t, baseline.Findings[1:2], result.Fixed)
+	})
+}
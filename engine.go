@@ -18,11 +18,13 @@ import (
 	"context"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
 	"sync"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/ZupIT/horusec-devkit/pkg/enums/vulnerability"
 
 	"github.com/ZupIT/horusec-engine/pool"
 )
@@ -40,135 +42,439 @@ type Finding struct {
 	Confidence     string
 	Description    string
 	SourceLocation Location
+
+	// Metadata references the complete advisory of the rule that reported the finding, like CWEs, CVEs, mitigation
+	// and references. It's shared by all findings of the same rule, so it should not be modified, and it can be nil
+	// when the rule doesn't have metadata
+	Metadata *Metadata
+
+	// Suppression holds the inline annotation that suppresses the finding, it's nil when the finding isn't suppressed
+	Suppression *Suppression
+
+	// Fingerprint is a stable identifier of the finding, see the Fingerprint function. It's set by the engine, and
+	// it's the hash used to accept the finding as a false positive or a risk
+	Fingerprint string
+
+	// Acceptance is set to vulnerability.FalsePositive or vulnerability.RiskAccepted when the fingerprint of the
+	// finding was accepted through WithFalsePositiveHashes or WithRiskAcceptHashes, otherwise it's empty
+	Acceptance vulnerability.Type
+
+	// BaselineState is set to BaselineNew or BaselineUnchanged when the engine compares the findings with a baseline
+	// informed through WithBaseline, otherwise it's empty
+	BaselineState BaselineState
 }
 
-// Location represents the location of the vulnerability in a file
+// Location represents the location of the vulnerability in a file. Lines and columns are 1-based, and the end
+// position, as well as the end offset, points right after the last character of the vulnerable code
 type Location struct {
-	Filename string
-	Line     int
-	Column   int
+	Filename  string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Offset    int // Offset holds the byte offset of the beginning of the vulnerable code in the file
+	EndOffset int // EndOffset holds the byte offset right after the end of the vulnerable code in the file
+
+	// FileLevel is set when the vulnerability is about the whole file, like a missing setting, so the location has
+	// only the file name, without line, column and code sample
+	FileLevel bool
 }
 
 // Engine contains all the engine necessary data
 type Engine struct {
-	poolSize   int
-	extensions []string
+	poolSize        int
+	extensions      []string
+	ignorePatterns  []string
+	includePatterns []string
+	gitIgnore       bool
+	errorPolicy     ErrorPolicy
+	sniffSize       int
+	dropSuppressed  bool
+
+	falsePositiveHashes map[string]bool
+	riskAcceptHashes    map[string]bool
+	dropAccepted        bool
+
+	baseline      *Baseline
+	dropUnchanged bool
+
+	changedLinesOnly bool
 }
 
 // NewEngine creates a new engine instance with all necessary data.
 // extensions argument represents which extension the engine should apply the rules
 // poolSize represents the number of go routines to open (Default is 10)
 func NewEngine(poolSize int, extensions ...string) *Engine {
-	return &Engine{
+	return NewEngineWithOptions(poolSize, extensions)
+}
+
+// NewEngineWithOptions creates a new engine instance like NewEngine, customizing its behavior with the options
+func NewEngineWithOptions(poolSize int, extensions []string, options ...Option) *Engine {
+	engine := &Engine{
 		poolSize:   poolSize,
 		extensions: extensions,
+		sniffSize:  DefaultBinarySniffSize,
+	}
+
+	for _, option := range options {
+		option(engine)
 	}
+
+	return engine
 }
 
-// Run walks through projectPath and runs the method Rule.Run in a pool of goroutines
-// if an error is found when executes Rule.Run method it cancels current running go routines and return
-// valid findings and the error
-// nolint:funlen,gocyclo // necessary complexity, breaking this function will lead to an even more complex code
+// Run walks through projectPath and runs the method Rule.RunFile in a pool of goroutines, each file is read only once
+// and shared across all rules. The project is read through os.DirFS, see RunFS, and when projectPath is a single file
+// only it is analyzed.
+// With the default FailFast error policy, if an error is found when reading a file or executing Rule.RunFile method it
+// cancels the analysis of the remaining files and returns valid findings and the error. With the ContinueOnError
+// policy, all files are analyzed and the errors found are returned together as ScanErrors. Panics of rules are
+// recovered as PanicErrors and returned together as ScanErrors with any policy, without canceling the analysis.
+// The context cancellation and deadline are respected while walking the project, scheduling the files and running
+// each rule, in this case the findings already collected are returned with the context error
 func (e *Engine) Run(ctx context.Context, projectPath string, rules ...Rule) ([]Finding, error) {
-	var findings []Finding
+	result, err := e.Scan(ctx, projectPath, rules...)
+	if err != nil {
+		return result.Findings, err
+	}
 
-	paths, err := e.getValidFilePaths(projectPath)
+	return result.Findings, result.Err()
+}
+
+// Scan works like Run, but returns a structured result containing the findings and the errors of each file and rule
+// that failed. The returned error is only set when the analysis was stopped, which happens if the project couldn't
+// be walked, the context is done or, with the FailFast error policy, any file or rule fails. When a baseline is
+// informed through WithBaseline, the result also contains the findings of the baseline that were not found
+func (e *Engine) Scan(ctx context.Context, projectPath string, rules ...Rule) (*ScanResult, error) {
+	target, err := e.newProjectTarget(projectPath)
 	if err != nil {
-		return nil, err
+		return new(ScanResult), err
 	}
 
+	return e.scan(ctx, target, newBaselineMatcher(e.baseline), rules)
+}
+
+// RunFS works like Run, but walks the whole file system, which can be an in-memory tree, like a fstest.MapFS, an
+// embed.FS or the contents of a zip file. The file names of the findings are the slash separated names of the files
+// in the file system, and the .gitignore files are read from it too
+func (e *Engine) RunFS(ctx context.Context, fsys fs.FS, rules ...Rule) ([]Finding, error) {
+	result, err := e.ScanFS(ctx, fsys, rules...)
+	if err != nil {
+		return result.Findings, err
+	}
+
+	return result.Findings, result.Err()
+}
+
+// ScanFS works like Scan, but walks the whole file system, see RunFS
+func (e *Engine) ScanFS(ctx context.Context, fsys fs.FS, rules ...Rule) (*ScanResult, error) {
+	return e.scan(ctx, e.newFSTarget(fsys, "", "."), newBaselineMatcher(e.baseline), rules)
+}
+
+// scan analyzes the target collecting the findings and errors into a ScanResult, the findings of the baseline that
+// were not matched are set as fixed when the analysis finishes without errors
+func (e *Engine) scan(ctx context.Context, target *target, matcher *baselineMatcher,
+	rules []Rule) (*ScanResult, error) {
+	result := new(ScanResult)
+
+	scanErrors, err := e.run(ctx, target, rules, matcher, collectFindings(&result.Findings))
+	result.Errors = scanErrors
+
+	if err == nil {
+		result.Fixed = matcher.fixed(scanErrors)
+	}
+
+	return result, err
+}
+
+// findingsHandler is called with the findings of each rule as soon as the rule finishes the analysis of a file. It's
+// called concurrently by the goroutines of the pool, and a returned error stops the analysis
+type findingsHandler func(ctx context.Context, findings []Finding) error
+
+// collectFindings returns a findings handler that appends the findings to the slice, it's safe to be called by the
+// goroutines of the pool
+func collectFindings(findings *[]Finding) findingsHandler {
 	mutex := new(sync.Mutex)
-	wg := sync.WaitGroup{}
 
-	workerPool, err := pool.NewPool(e.poolSize)
+	return func(_ context.Context, newFindings []Finding) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		*findings = append(*findings, newFindings...)
+
+		return nil
+	}
+}
+
+// handleIfAny calls next with the findings, unless there are no findings to handle
+func handleIfAny(ctx context.Context, next findingsHandler, findings []Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	return next(ctx, findings)
+}
+
+// fileAnalyzer analyzes the file with the name, it's called concurrently by the goroutines of the pool
+type fileAnalyzer func(ctx context.Context, name string) error
+
+// target describes what an analysis scans: the files of fsys returned by listPaths and the project path, which is
+// joined with the names of the files to report them, and is used to compute the relative paths of the findings. When
+// lines is set, only the findings on the changed lines of each file are reported
+type target struct {
+	fsys        fs.FS
+	projectPath string
+	listPaths   func(ctx context.Context) ([]string, error)
+	lines       map[string][]LineRange
+}
+
+// newProjectTarget creates a target that walks the whole project path looking for the files to analyze, or that
+// analyzes only the project path when it's a file
+func (e *Engine) newProjectTarget(projectPath string) (*target, error) {
+	info, err := os.Stat(projectPath)
 	if err != nil {
 		return nil, err
 	}
 
-	defer workerPool.Release()
+	if info.IsDir() {
+		return e.newFSTarget(os.DirFS(projectPath), projectPath, "."), nil
+	}
+
+	dir := filepath.Dir(projectPath)
+
+	return e.newFSTarget(os.DirFS(dir), dir, filepath.Base(projectPath)), nil
+}
 
-	group, _ := errgroup.WithContext(ctx)
+// newFSTarget creates a target that walks the file system from root looking for the files to analyze
+func (e *Engine) newFSTarget(fsys fs.FS, projectPath, root string) *target {
+	return &target{
+		fsys:        fsys,
+		projectPath: projectPath,
+		listPaths: func(ctx context.Context) ([]string, error) {
+			return e.getValidFilePaths(ctx, fsys, root)
+		},
+	}
+}
 
-	wg.Add(len(paths))
+// filePath returns the path used to report the file with the name, which is the name itself when there is no project
+// path
+func (t *target) filePath(name string) string {
+	if t.projectPath == "" {
+		return name
+	}
 
-	for _, path := range paths {
-		pathCopy := path
+	return filepath.Join(t.projectPath, filepath.FromSlash(name))
+}
 
-		errSubmit := workerPool.Submit(func() {
-			group.Go(func() error {
-				defer wg.Done()
+// filterLines returns a findings handler that removes the findings that are not on the lines of the file with the
+// name before calling next, when the target restricts the lines of the files
+func (t *target) filterLines(name string, next findingsHandler) findingsHandler {
+	if t.lines == nil {
+		return next
+	}
+
+	return filterChangedLines(t.lines[name], next)
+}
+
+// run lists the files of the target and analyzes each one of them in the pool of goroutines, handling the findings
+// of each rule with handleFindings, after setting their fingerprints, matching them with the baseline and removing
+// the ones that should not be reported. The findings are matched with the baseline before being filtered by the
+// changed lines, so the findings of the baseline on the other lines are not reported as fixed. Errors of files and
+// rules are handled according to the engine error policy, the ones that didn't stop the analysis are returned in the
+// slice
+func (e *Engine) run(ctx context.Context, target *target, rules []Rule, matcher *baselineMatcher,
+	handleFindings findingsHandler) ([]*ScanError, error) {
+	paths, err := target.listPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-				newFindings, errRunRule := e.runRule(rules, pathCopy)
-				if errRunRule != nil {
-					return errRunRule
-				}
+	collector := newErrorCollector(e.errorPolicy)
 
-				mutex.Lock()
-				findings = append(findings, newFindings...)
-				mutex.Unlock()
+	err = e.runInPool(ctx, paths, func(ctx context.Context, name string) error {
+		handle := e.prepareFindings(target.projectPath, matcher, target.filterLines(name, handleFindings))
 
-				return errRunRule
-			})
-		})
-		if errSubmit != nil {
-			return nil, errSubmit
+		return e.runRule(ctx, rules, target, name, handle, collector.handle)
+	})
+
+	return collector.errors, err
+}
+
+// runInPool analyzes each path in a pool of goroutines and waits all of them to finish. When the analysis of any
+// path fails or the context is done, the paths not analyzed yet are skipped and the first error is returned
+func (e *Engine) runInPool(ctx context.Context, paths []string, analyze fileAnalyzer) error {
+	workerPool, err := pool.NewPool(e.poolSize)
+	if err != nil {
+		return err
+	}
+
+	defer workerPool.Release()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	firstErr := newFirstError(cancel)
+	submitPaths(ctx, workerPool, paths, firstErr, analyze)
+
+	return firstErr.get(ctx)
+}
+
+// submitPaths submits the analysis of each path to the worker pool until the context is done, and waits the
+// submitted ones to finish. The errors of the analysis and of the submission are set in firstErr
+func submitPaths(ctx context.Context, workerPool *pool.Pool, paths []string, firstErr *firstError,
+	analyze fileAnalyzer) {
+	wg := sync.WaitGroup{}
+
+	for index := 0; index < len(paths) && ctx.Err() == nil; index++ {
+		wg.Add(1)
+
+		if err := workerPool.Submit(newAnalysisTask(ctx, &wg, firstErr, paths[index], analyze)); err != nil {
+			wg.Done()
+			firstErr.set(err)
 		}
 	}
 
 	wg.Wait()
-	err = group.Wait()
+}
 
-	return findings, err
+// newAnalysisTask creates the task submitted to the worker pool to analyze the path, which is skipped when the
+// context is already done
+func newAnalysisTask(ctx context.Context, wg *sync.WaitGroup, firstErr *firstError, path string,
+	analyze fileAnalyzer) func() {
+	return func() {
+		defer wg.Done()
+
+		if ctx.Err() == nil {
+			firstErr.set(analyze(ctx, path))
+		}
+	}
 }
 
-func (e *Engine) runRule(rules []Rule, pathCopy string) ([]Finding, error) {
-	var findings []Finding
+// runRule reads the file content from the target file system only once and runs the rules with it through runRules.
+// Errors reading the file are handled by handleError too
+func (e *Engine) runRule(ctx context.Context, rules []Rule, target *target, name string,
+	handleFindings findingsHandler, handleError errorHandler) error {
+	path := target.filePath(name)
+
+	content, err := fs.ReadFile(target.fsys, name)
+	if err != nil {
+		return handleError(&ScanError{Path: path, Err: err})
+	}
+
+	return e.runRules(ctx, rules, NewFile(path, content), handleFindings, handleError)
+}
+
+// runRules runs each one of the rules with the same loaded file, so the file and any data that the rules derive from
+// it are shared between them. The findings of each rule are handled as soon as the rule finishes, and the errors of
+// the rules, including recovered panics, are handled by handleError, which decides if the analysis should stop.
+// Binary files are skipped before running any rule. The context is checked before running each rule, so a canceled
+// analysis doesn't need to wait all rules to finish
+func (e *Engine) runRules(ctx context.Context, rules []Rule, file *File, handleFindings findingsHandler,
+	handleError errorHandler) error {
+	if file.isBinaryWithSniffSize(e.sniffSize) {
+		return nil
+	}
 
 	for _, rule := range rules {
-		f, err := rule.Run(pathCopy)
-		if err != nil {
-			return nil, err
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		findings = append(findings, f...)
+		if err := runFile(ctx, rule, file, handleFindings, handleError); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runFile runs the rule with the file, handling its findings with handleFindings and its error with handleError
+func runFile(ctx context.Context, rule Rule, file *File, handleFindings findingsHandler,
+	handleError errorHandler) error {
+	findings, err := runFileSafely(rule, file)
+	if err != nil {
+		return handleError(&ScanError{Path: file.Path, RuleID: getRuleID(rule), Err: err})
 	}
 
-	return findings, nil
+	return handleIfAny(ctx, handleFindings, findings)
 }
 
-// getValidFilePaths this function will walk the project directory and will look for files that match the extensions
-// informed during the initialization of the engine and return a slice with it.
-// Directories, sys links and files with extensions that are not in Engine.extensions struct wil be ignored
-func (e *Engine) getValidFilePaths(projectPath string) ([]string, error) {
+// runFileSafely runs the rule with the file recovering any panic of the rule, which is returned as a *PanicError,
+// so a bug in a single rule doesn't crash the whole process
+func runFileSafely(rule Rule, file *File) (findings []Finding, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Value: value, Stack: debug.Stack()}
+		}
+	}()
+
+	return rule.RunFile(file)
+}
+
+// getValidFilePaths this function will walk the file system from root and will look for files that match the
+// extensions informed during the initialization of the engine and return a slice with their names.
+// Directories, sys links and files with extensions that are not in Engine.extensions struct wil be ignored, as well
+// as files and directories ignored by the engine ignore patterns and .gitignore files.
+// The walk stops with the context error as soon as the context is done
+func (e *Engine) getValidFilePaths(ctx context.Context, fsys fs.FS, root string) ([]string, error) {
 	var validPaths []string
 
-	err := filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
-		if err != nil || e.isInvalidFilePath(path, entry) {
+	filter, err := e.newPathFilter(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fs.WalkDir(fsys, root, e.collectValidPaths(ctx, filter, &validPaths))
+
+	return validPaths, err
+}
+
+// collectValidPaths returns the function that walks the file system appending the names of the files that should be
+// analyzed to validPaths
+func (e *Engine) collectValidPaths(ctx context.Context, filter *pathFilter, validPaths *[]string) fs.WalkDirFunc {
+	return func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
 			return err
 		}
 
-		validPaths = append(validPaths, path)
+		valid, err := e.isValidWalkedPath(ctx, filter, name, entry)
+		if valid {
+			*validPaths = append(*validPaths, name)
+		}
 
-		return nil
-	})
+		return err
+	}
+}
 
-	return validPaths, err
+// isValidWalkedPath checks if the file found while walking the file system should be analyzed. Directories are
+// walked by the filter, which returns fs.SkipDir for the ignored ones
+func (e *Engine) isValidWalkedPath(ctx context.Context, filter *pathFilter, name string,
+	entry fs.DirEntry) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	if entry.IsDir() {
+		return false, filter.walkDir(name)
+	}
+
+	return !e.isInvalidFilePath(name, entry) && !filter.isIgnored(name, false), nil
 }
 
 // isInvalidFilePath contains a list of validations to check if a path needs to be analyzed. It will ignore directories,
 // sysLinks, extensions that don't match the necessary ones, and .git files
-func (e *Engine) isInvalidFilePath(path string, entry fs.DirEntry) bool {
+func (e *Engine) isInvalidFilePath(name string, entry fs.DirEntry) bool {
 	return entry.IsDir() ||
 		entry.Type() == fs.ModeSymlink ||
-		e.isInvalidExtension(path) ||
-		e.isFileFromGitFolder(path)
+		e.isInvalidExtension(name) ||
+		e.isFileFromGitFolder(name)
 }
 
 // isInvalidExtension verify if the filepath contains a valid file extension.
 // The valid extensions are the ones that should be analyzed, and are passed during the initialization of the engine
-func (e *Engine) isInvalidExtension(path string) bool {
+func (e *Engine) isInvalidExtension(name string) bool {
 	for _, ext := range e.extensions {
-		if ext == filepath.Ext(path) || ext == AcceptAnyExtension {
+		if ext == path.Ext(name) || ext == AcceptAnyExtension {
 			return false
 		}
 	}
@@ -177,6 +483,6 @@ func (e *Engine) isInvalidExtension(path string) bool {
 }
 
 // isFileFromGitFolder check if a file is in a .git folder
-func (e *Engine) isFileFromGitFolder(path string) bool {
-	return strings.Contains(path, ".git"+string(os.PathSeparator))
+func (e *Engine) isFileFromGitFolder(name string) bool {
+	return strings.Contains(name, ".git/")
 }
@@ -17,10 +17,15 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ruleMock struct {
@@ -41,6 +46,11 @@ func (r *ruleMock) Run(_ string) ([]Finding, error) {
 	return r.findings, r.err
 }
 
+// RunFile has the same behavior of Run, but using the file loaded by the engine
+func (r *ruleMock) RunFile(_ *File) ([]Finding, error) {
+	return r.findings, r.err
+}
+
 func TestEngineRun(t *testing.T) {
 	testcases := []struct {
 		name             string
@@ -123,3 +133,227 @@ func TestEngineRun(t *testing.T) {
 		})
 	}
 }
+
+// cancelRuleMock cancels the context after running on a given number of files
+type cancelRuleMock struct {
+	mutex  sync.Mutex
+	runs   int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (r *cancelRuleMock) Run(_ string) ([]Finding, error) {
+	return r.RunFile(nil)
+}
+
+func (r *cancelRuleMock) RunFile(_ *File) ([]Finding, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.runs++
+	if r.runs == r.after {
+		r.cancel()
+	}
+
+	return []Finding{{}}, nil
+}
+
+// newTestProject creates a temporary project with the informed number of go files
+func newTestProject(t *testing.T, files int) string {
+	projectPath := t.TempDir()
+
+	for i := 0; i < files; i++ {
+		path := filepath.Join(projectPath, fmt.Sprintf("file%d.go", i))
+		if err := os.WriteFile(path, []byte("package test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return projectPath
+}
+
+func TestEngineRunWithContext(t *testing.T) {
+	t.Run("Should return context error without findings when context is already canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		findings, err := NewEngine(1, ".go").Run(ctx, newTestProject(t, 10), newRuleMock([]Finding{{}}, nil))
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, findings)
+	})
+
+	t.Run("Should stop the analysis and return collected findings when context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rule := &cancelRuleMock{after: 5, cancel: cancel}
+
+		findings, err := NewEngine(1, ".go").Run(ctx, newTestProject(t, 100), rule)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotEmpty(t, findings)
+		assert.Less(t, len(findings), 100)
+		assert.Less(t, rule.runs, 100)
+	})
+
+	t.Run("Should return deadline exceeded error when context deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		<-ctx.Done()
+
+		_, err := NewEngine(1, ".go").Run(ctx, newTestProject(t, 10), newRuleMock([]Finding{{}}, nil))
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Should return all findings when context is not canceled", func(t *testing.T) {
+		findings, err := NewEngine(1, ".go").Run(context.Background(), newTestProject(t, 10),
+			newRuleMock([]Finding{{}}, nil))
+
+		assert.NoError(t, err)
+		assert.Len(t, findings, 10)
+	})
+}
+
+// pathErrorRuleMock returns an error when analyzing the informed file, and one finding for any other file
+type pathErrorRuleMock struct {
+	Metadata
+	errorPath string
+}
+
+func (r *pathErrorRuleMock) Run(path string) ([]Finding, error) {
+	return r.RunFile(NewFile(path, nil))
+}
+
+func (r *pathErrorRuleMock) RunFile(file *File) ([]Finding, error) {
+	if filepath.Base(file.Path) == r.errorPath {
+		return nil, errors.New("test error")
+	}
+
+	return []Finding{{ID: r.ID}}, nil
+}
+
+func TestEngineScan(t *testing.T) {
+	t.Run("Should continue the analysis and collect errors with ContinueOnError policy", func(t *testing.T) {
+		engine := NewEngineWithOptions(2, []string{".go"}, WithErrorPolicy(ContinueOnError))
+		rule := &pathErrorRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, errorPath: "file1.go"}
+
+		result, err := engine.Scan(context.Background(), newTestProject(t, 10), rule)
+		assert.NoError(t, err)
+		assert.Len(t, result.Findings, 9)
+		assert.Len(t, result.Errors, 1)
+
+		scanErr := result.Errors[0]
+		assert.Equal(t, "file1.go", filepath.Base(scanErr.Path))
+		assert.Equal(t, "HS-TEST-1", scanErr.RuleID)
+		assert.EqualError(t, scanErr.Err, "test error")
+
+		var scanErrors ScanErrors
+		assert.ErrorAs(t, result.Err(), &scanErrors)
+	})
+
+	t.Run("Should run the others rules of the file when a rule fails with ContinueOnError", func(t *testing.T) {
+		engine := NewEngineWithOptions(2, []string{".go"}, WithErrorPolicy(ContinueOnError))
+		failingRule := &pathErrorRuleMock{errorPath: "file0.go"}
+
+		result, err := engine.Scan(context.Background(), newTestProject(t, 1), failingRule,
+			newRuleMock([]Finding{{}}, nil))
+		assert.NoError(t, err)
+		assert.Len(t, result.Findings, 1)
+		assert.Len(t, result.Errors, 1)
+		assert.Empty(t, result.Errors[0].RuleID)
+	})
+
+	t.Run("Should stop the analysis with a scan error with FailFast policy", func(t *testing.T) {
+		rule := &pathErrorRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, errorPath: "file1.go"}
+
+		result, err := NewEngine(1, ".go").Scan(context.Background(), newTestProject(t, 10), rule)
+
+		var scanErr *ScanError
+		assert.ErrorAs(t, err, &scanErr)
+		assert.Equal(t, "HS-TEST-1", scanErr.RuleID)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("Should return errors of Run together when using ContinueOnError policy", func(t *testing.T) {
+		engine := NewEngineWithOptions(2, []string{".go"}, WithErrorPolicy(ContinueOnError))
+		rule := &pathErrorRuleMock{errorPath: "file1.go"}
+
+		findings, err := engine.Run(context.Background(), newTestProject(t, 10), rule)
+		assert.Len(t, findings, 9)
+
+		var scanErrors ScanErrors
+		assert.ErrorAs(t, err, &scanErrors)
+		assert.Len(t, scanErrors, 1)
+	})
+}
+
+// panicRuleMock panics when analyzing the informed file, and returns one finding for any other file
+type panicRuleMock struct {
+	Metadata
+	panicPath string
+}
+
+func (r *panicRuleMock) Run(path string) ([]Finding, error) {
+	return r.RunFile(NewFile(path, nil))
+}
+
+func (r *panicRuleMock) RunFile(file *File) ([]Finding, error) {
+	if filepath.Base(file.Path) == r.panicPath {
+		_ = file.Content[len(file.Content)]
+	}
+
+	return []Finding{{ID: r.ID}}, nil
+}
+
+func TestEngineRunWithPanic(t *testing.T) {
+	t.Run("Should recover the panic and keep scanning with ContinueOnError policy", func(t *testing.T) {
+		engine := NewEngineWithOptions(2, []string{".go"}, WithErrorPolicy(ContinueOnError))
+		rule := &panicRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, panicPath: "file3.go"}
+
+		result, err := engine.Scan(context.Background(), newTestProject(t, 10), rule)
+		assert.NoError(t, err)
+		assert.Len(t, result.Findings, 9)
+
+		if !assert.Len(t, result.Errors, 1) {
+			return
+		}
+
+		scanErr := result.Errors[0]
+		assert.Equal(t, "file3.go", filepath.Base(scanErr.Path))
+		assert.Equal(t, "HS-TEST-1", scanErr.RuleID)
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, scanErr, &panicErr)
+		assert.Contains(t, panicErr.Error(), "index out of range")
+		assert.Contains(t, string(panicErr.Stack), "panicRuleMock")
+	})
+
+	t.Run("Should recover the panic and keep scanning with FailFast policy", func(t *testing.T) {
+		rule := &panicRuleMock{panicPath: "file3.go"}
+
+		result, err := NewEngine(2, ".go").Scan(context.Background(), newTestProject(t, 10), rule)
+		assert.NoError(t, err)
+		assert.Len(t, result.Findings, 9)
+		require.Len(t, result.Errors, 1)
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, result.Errors[0], &panicErr)
+	})
+
+	t.Run("Should return the recovered panic together with the findings of Run with FailFast policy", func(t *testing.T) {
+		rule := &panicRuleMock{panicPath: "file3.go"}
+
+		findings, err := NewEngine(2, ".go").Run(context.Background(), newTestProject(t, 10), rule)
+		assert.Len(t, findings, 9)
+
+		var scanErrors ScanErrors
+		require.ErrorAs(t, err, &scanErrors)
+		require.Len(t, scanErrors, 1)
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, scanErrors[0], &panicErr)
+	})
+}
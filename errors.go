@@ -0,0 +1,159 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrorPolicy represents how the engine handles errors of files and rules during the analysis
+type ErrorPolicy int
+
+const (
+	// FailFast stops the whole analysis when any file can't be read or any rule returns an error. Panics of rules are
+	// the exception, they are always collected, so a bug in a single rule doesn't stop the analysis of the other files
+	FailFast ErrorPolicy = iota
+
+	// ContinueOnError keeps analyzing all files when a file can't be read or a rule fails, collecting the errors
+	ContinueOnError
+)
+
+// ScanError represents an error that happened while analyzing a file
+type ScanError struct {
+	Path   string // Path holds the path of the file being analyzed
+	RuleID string // RuleID holds the ID of the rule that failed, empty when the file itself failed (e.g. reading it)
+	Err    error  // Err holds the cause of the error
+}
+
+// Error returns the error message with the path and the rule ID, when present
+func (e *ScanError) Error() string {
+	if e.RuleID == "" {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("%s: rule %s: %v", e.Path, e.RuleID, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// ScanErrors represents all errors of files and rules that didn't stop the analysis
+type ScanErrors []*ScanError
+
+// Error returns the number of errors and the message of each one of them
+func (e ScanErrors) Error() string {
+	messages := make([]string, 0, len(e))
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("%d errors during the analysis: %s", len(e), strings.Join(messages, "; "))
+}
+
+// PanicError represents a panic recovered while running a rule, it's returned as the cause of a ScanError, so the
+// path of the file and the rule ID are also available
+type PanicError struct {
+	Value interface{} // Value holds the value passed to panic
+	Stack []byte      // Stack holds the stack trace of the goroutine when the panic was recovered
+}
+
+// Error returns the message with the value passed to panic
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// errorHandler is called with the errors of files and rules, if it returns an error the analysis is stopped
+type errorHandler func(err *ScanError) error
+
+// errorCollector handles the errors of files and rules according to the error policy, collecting the ones that
+// should not stop the analysis
+type errorCollector struct {
+	mutex  sync.Mutex
+	policy ErrorPolicy
+	errors []*ScanError
+}
+
+// newErrorCollector creates a new error collector for the policy
+func newErrorCollector(policy ErrorPolicy) *errorCollector {
+	return &errorCollector{policy: policy}
+}
+
+// handle returns the error to stop the analysis when the policy is FailFast, otherwise collects it. Recovered panics
+// are collected with any policy
+func (c *errorCollector) handle(err *ScanError) error {
+	if c.policy == FailFast && !isPanic(err) {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.errors = append(c.errors, err)
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// isPanic checks if the cause of the error is a panic recovered while running a rule
+func isPanic(err error) bool {
+	var panicErr *PanicError
+
+	return errors.As(err, &panicErr)
+}
+
+// firstError holds the first error that happened during an analysis, canceling the analysis when it's set
+type firstError struct {
+	mutex  sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+// newFirstError creates a new firstError that calls cancel when the first error is set
+func newFirstError(cancel context.CancelFunc) *firstError {
+	return &firstError{cancel: cancel}
+}
+
+// set stores the error if it's the first one, nil errors are ignored
+func (f *firstError) set(err error) {
+	if err == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.err == nil {
+		f.err = err
+		f.cancel()
+	}
+}
+
+// get returns the first error set. If none was set, but the context is done, the context error is returned, so
+// callers know that the analysis didn't finish
+func (f *firstError) get(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanError(t *testing.T) {
+	t.Run("Should return message with path and rule id", func(t *testing.T) {
+		err := &ScanError{Path: "main.go", RuleID: "HS-GO-1", Err: errors.New("test error")}
+		assert.EqualError(t, err, "main.go: rule HS-GO-1: test error")
+	})
+
+	t.Run("Should return message without rule id when file failed", func(t *testing.T) {
+		err := &ScanError{Path: "main.go", Err: fs.ErrPermission}
+		assert.EqualError(t, err, "main.go: permission denied")
+		assert.ErrorIs(t, err, fs.ErrPermission)
+	})
+
+	t.Run("Should return message of all errors", func(t *testing.T) {
+		err := ScanErrors{
+			{Path: "main.go", Err: fs.ErrPermission},
+			{Path: "server.go", RuleID: "HS-GO-1", Err: errors.New("test error")},
+		}
+
+		assert.EqualError(t, err,
+			"2 errors during the analysis: main.go: permission denied; server.go: rule HS-GO-1: test error")
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import "sync"
+
+// File represents a file loaded by the engine. Its content is read only once and shared across all rules that are
+// analyzing it, so rules must not modify the content
+type File struct {
+	Path    string // Path holds the path of the file as found by the engine
+	Content []byte // Content holds all the file content
+
+	mutex  sync.Mutex
+	values map[interface{}]interface{}
+}
+
+// NewFile creates a new file with the content already loaded
+func NewFile(path string, content []byte) *File {
+	return &File{
+		Path:    path,
+		Content: content,
+		values:  make(map[interface{}]interface{}),
+	}
+}
+
+// Value returns the value associated with the key, calling create to build it only on the first access. It allows
+// rules of the same kind to share data derived from the file content, like a line index, instead of building it again
+// for each rule. The key should be an unexported type of the package that is calling it to avoid collisions
+func (f *File) Value(key interface{}, create func() (interface{}, error)) (interface{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if value, ok := f.values[key]; ok {
+		return value, nil
+	}
+
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	f.setValue(key, value)
+
+	return value, nil
+}
+
+// setValue associates the value with the key, creating the values of the files that weren't created by NewFile
+func (f *File) setValue(key, value interface{}) {
+	if f.values == nil {
+		f.values = make(map[interface{}]interface{})
+	}
+
+	f.values[key] = value
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fileValueKey struct{}
+
+func TestFileValue(t *testing.T) {
+	t.Run("Should create the value only on the first access", func(t *testing.T) {
+		file := NewFile("test.go", []byte("package test"))
+		calls := 0
+
+		create := func() (interface{}, error) {
+			calls++
+
+			return len(file.Content), nil
+		}
+
+		for i := 0; i < 3; i++ {
+			value, err := file.Value(fileValueKey{}, create)
+			assert.NoError(t, err)
+			assert.Equal(t, 12, value)
+		}
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should not cache the value when failed to create it", func(t *testing.T) {
+		file := NewFile("test.go", nil)
+		calls := 0
+
+		create := func() (interface{}, error) {
+			calls++
+
+			return nil, errors.New("test error")
+		}
+
+		_, err := file.Value(fileValueKey{}, create)
+		assert.Error(t, err)
+
+		_, err = file.Value(fileValueKey{}, create)
+		assert.Error(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Should create the value when file was not created by NewFile", func(t *testing.T) {
+		file := &File{Path: "test.go"}
+
+		value, err := file.Value(fileValueKey{}, func() (interface{}, error) {
+			return "value", nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// gitIgnoreFilename is the name of the files containing git ignore patterns
+const gitIgnoreFilename = ".gitignore"
+
+// pathFilter decides which files and directories found while walking a project should be ignored, according to the
+// engine ignore and include patterns and the .gitignore files found during the walk. The paths are the slash separated
+// names of the file system of the project, which are already relative to the project root. When the project is a
+// single file, the file system is its directory, so the patterns are matched against the file name
+type pathFilter struct {
+	fsys            fs.FS
+	ignorePatterns  []string
+	includePatterns []string
+	gitIgnore       bool
+	gitIgnores      map[string]*gitIgnore // gitIgnores holds the parsed .gitignore files by its relative directory
+}
+
+// newPathFilter creates the filter for the file system, validating all engine patterns
+func (e *Engine) newPathFilter(fsys fs.FS) (*pathFilter, error) {
+	for _, pattern := range append(e.ignorePatterns, e.includePatterns...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+
+	return &pathFilter{
+		fsys:            fsys,
+		ignorePatterns:  e.ignorePatterns,
+		includePatterns: e.includePatterns,
+		gitIgnore:       e.gitIgnore,
+		gitIgnores:      make(map[string]*gitIgnore),
+	}, nil
+}
+
+// walkDir decides if the directory should be walked, returning fs.SkipDir if it's ignored. When the directory is
+// walked, its .gitignore file is loaded to be used by the files and directories inside it
+func (p *pathFilter) walkDir(dir string) error {
+	if p.isIgnored(dir, true) {
+		return fs.SkipDir
+	}
+
+	if !p.gitIgnore {
+		return nil
+	}
+
+	return p.loadGitIgnore(dir)
+}
+
+// loadGitIgnore loads the .gitignore file of the directory, if it has one
+func (p *pathFilter) loadGitIgnore(dir string) error {
+	content, err := fs.ReadFile(p.fsys, path.Join(dir, gitIgnoreFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.gitIgnores[dir] = parseGitIgnore(dir, content)
+
+	return nil
+}
+
+// isIgnored checks if the path should not be analyzed. The project root is never ignored, and include patterns
+// are only applied to files
+func (p *pathFilter) isIgnored(relativePath string, isDir bool) bool {
+	if relativePath == "." {
+		return false
+	}
+
+	if matchAny(p.ignorePatterns, relativePath) || p.isGitIgnored(relativePath, isDir) {
+		return true
+	}
+
+	return !isDir && p.isNotIncluded(relativePath)
+}
+
+// isNotIncluded checks if there are include patterns and the path doesn't match any of them
+func (p *pathFilter) isNotIncluded(relativePath string) bool {
+	return len(p.includePatterns) > 0 && !matchAny(p.includePatterns, relativePath)
+}
+
+// isGitIgnored applies the .gitignore files of all parent directories of the path, from the project root to the
+// nearest one. As git does, the last pattern that matches the path decides if it's ignored or not
+func (p *pathFilter) isGitIgnored(relativePath string, isDir bool) bool {
+	ignored := false
+
+	for _, dir := range parentDirs(relativePath) {
+		gitIgnoreFile, ok := p.gitIgnores[dir]
+		if !ok {
+			continue
+		}
+
+		if matched, isIgnored := gitIgnoreFile.match(relativePath, isDir); matched {
+			ignored = isIgnored
+		}
+	}
+
+	return ignored
+}
+
+// parentDirs returns all parent directories of a relative slash separated path, from the root (".") to the nearest
+func parentDirs(relativePath string) []string {
+	dir := path.Dir(relativePath)
+	if dir == "." {
+		return []string{"."}
+	}
+
+	return append(parentDirs(dir), dir)
+}
+
+// matchAny checks if the path matches any of the doublestar patterns, patterns must be already validated
+func matchAny(patterns []string, relativePath string) bool {
+	for _, pattern := range patterns {
+		if doublestar.MatchUnvalidated(pattern, relativePath) {
+			return true
+		}
+	}
+
+	return false
+}
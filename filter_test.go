@@ -0,0 +1,161 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestProjectWithFiles creates a temporary project with the files and its contents
+func newTestProjectWithFiles(t *testing.T, files map[string]string) string {
+	projectPath := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(projectPath, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return projectPath
+}
+
+// relativePaths converts the paths found by the engine into paths relative to the project using forward slashes
+func relativePaths(t *testing.T, projectPath string, paths []string) []string {
+	relative := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		relativePath, err := filepath.Rel(projectPath, path)
+		assert.NoError(t, err)
+
+		relative = append(relative, filepath.ToSlash(relativePath))
+	}
+
+	sort.Strings(relative)
+
+	return relative
+}
+
+func TestGetValidFilePathsWithFilters(t *testing.T) {
+	files := map[string]string{
+		".gitignore":                  "dist/\n*.min.js\n",
+		"main.go":                     "",
+		"main_test.go":                "",
+		"vendor/lib/lib.go":           "",
+		"node_modules/pkg/index.js":   "",
+		"web/app.js":                  "",
+		"web/app.min.js":              "",
+		"web/.gitignore":              "!app.min.js\nlegacy.js\n",
+		"web/legacy.js":               "",
+		"dist/bundle.js":              "",
+		"internal/handler/handler.go": "",
+	}
+
+	testCases := []struct {
+		name          string
+		options       []Option
+		expectedPaths []string
+	}{
+		{
+			name:    "Should ignore files and directories matching ignore patterns",
+			options: []Option{WithIgnorePatterns("**/*_test.go", "vendor/**", "**/node_modules/**")},
+			expectedPaths: []string{
+				".gitignore", "dist/bundle.js", "internal/handler/handler.go", "main.go", "web/.gitignore",
+				"web/app.js", "web/app.min.js", "web/legacy.js",
+			},
+		},
+		{
+			name:    "Should analyze only files matching include patterns",
+			options: []Option{WithIncludePatterns("**/*.go"), WithIgnorePatterns("vendor/**")},
+			expectedPaths: []string{
+				"internal/handler/handler.go", "main.go", "main_test.go",
+			},
+		},
+		{
+			name:    "Should honor .gitignore files found while walking",
+			options: []Option{WithGitIgnore(), WithIgnorePatterns("**/node_modules/**", "vendor/**")},
+			expectedPaths: []string{
+				".gitignore", "internal/handler/handler.go", "main.go", "main_test.go", "web/.gitignore",
+				"web/app.js", "web/app.min.js",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			projectPath := newTestProjectWithFiles(t, files)
+			engine := NewEngineWithOptions(1, []string{AcceptAnyExtension}, testCase.options...)
+
+			paths, err := engine.getValidFilePaths(context.Background(), os.DirFS(projectPath), ".")
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedPaths, paths)
+		})
+	}
+
+	t.Run("Should return error when pattern is invalid", func(t *testing.T) {
+		engine := NewEngineWithOptions(1, []string{AcceptAnyExtension}, WithIgnorePatterns("[a-"))
+
+		_, err := engine.getValidFilePaths(context.Background(), os.DirFS(newTestProject(t, 1)), ".")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetValidFilePathsOfSingleFile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filename      string
+		options       []Option
+		expectedPaths []string
+	}{
+		{
+			name:     "Should ignore the file when its name matches an ignore pattern",
+			filename: "app.log",
+			options:  []Option{WithIgnorePatterns("**/*.log")},
+		},
+		{
+			name:     "Should ignore the file when its name doesn't match the include patterns",
+			filename: "app.log",
+			options:  []Option{WithIncludePatterns("**/*.go")},
+		},
+		{
+			name:          "Should analyze the file when its name matches the include patterns",
+			filename:      "main.go",
+			options:       []Option{WithIncludePatterns("**/*.go"), WithIgnorePatterns("**/*.log")},
+			expectedPaths: []string{"main.go"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			projectPath := newTestProjectWithFiles(t, map[string]string{testCase.filename: ""})
+			engine := NewEngineWithOptions(1, []string{AcceptAnyExtension}, testCase.options...)
+
+			paths, err := engine.getValidFilePaths(context.Background(), os.DirFS(projectPath), testCase.filename)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedPaths, paths)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/vulnerability"
+)
+
+// Fingerprint returns a stable identifier of a finding, computed from the rule ID, the path of the file relative to
+// the project and the code sample. The path separators and the whitespaces of the code sample are normalized, so
+// the fingerprint is the same in any operating system and doesn't change when the code is moved to other lines or
+// re-indented
+func Fingerprint(ruleID, relativePath, codeSample string) string {
+	hash := sha256.New()
+
+	for _, value := range []string{ruleID, normalizePath(relativePath), strings.Join(strings.Fields(codeSample), " ")} {
+		_, _ = hash.Write([]byte(value))
+		_, _ = hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// normalizePath cleans the path and converts its separators into forward slashes
+func normalizePath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+// relativeFindingPath returns the path of the file relative to the project path. When the file is the project path
+// itself, only its name is used, and paths outside the project are kept as they are
+func relativeFindingPath(projectPath, path string) string {
+	relative, err := filepath.Rel(projectPath, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return path
+	}
+
+	if relative == "." {
+		return filepath.Base(path)
+	}
+
+	return relative
+}
+
+// prepareFindings returns a findings handler that sets the fingerprint, the acceptance and the baseline state of
+// each finding and removes the ones that should not be reported, like the suppressed ones when
+// WithoutSuppressedFindings is used, before calling next. The findings are copied, since their slice can be shared
+// by the rule
+func (e *Engine) prepareFindings(projectPath string, matcher *baselineMatcher, next findingsHandler) findingsHandler {
+	return func(ctx context.Context, findings []Finding) error {
+		prepared := make([]Finding, 0, len(findings))
+
+		for index := range findings {
+			finding := findings[index]
+			e.prepareFinding(projectPath, matcher, &finding)
+
+			if e.isReported(&finding) {
+				prepared = append(prepared, finding)
+			}
+		}
+
+		return handleIfAny(ctx, next, prepared)
+	}
+}
+
+// prepareFinding sets the fingerprint of the finding, when the rule didn't set it, its acceptance and its baseline
+// state
+func (e *Engine) prepareFinding(projectPath string, matcher *baselineMatcher, finding *Finding) {
+	if finding.Fingerprint == "" {
+		finding.Fingerprint = Fingerprint(finding.ID,
+			relativeFindingPath(projectPath, finding.SourceLocation.Filename), finding.CodeSample)
+	}
+
+	finding.Acceptance = e.getAcceptance(finding.Fingerprint)
+	finding.BaselineState = matcher.match(finding.Fingerprint)
+}
+
+// getAcceptance returns if the fingerprint was accepted as a false positive or a risk, or an empty type if it wasn't
+func (e *Engine) getAcceptance(fingerprint string) vulnerability.Type {
+	if e.falsePositiveHashes[fingerprint] {
+		return vulnerability.FalsePositive
+	}
+
+	if e.riskAcceptHashes[fingerprint] {
+		return vulnerability.RiskAccepted
+	}
+
+	return ""
+}
+
+// isReported checks if the finding should be reported according to the engine options
+func (e *Engine) isReported(finding *Finding) bool {
+	if e.dropSuppressed && finding.Suppression != nil {
+		return false
+	}
+
+	if e.isDroppedUnchanged(finding) {
+		return false
+	}
+
+	return !e.dropAccepted || finding.Acceptance == ""
+}
+
+// isDroppedUnchanged checks if the finding is unchanged from the baseline and the engine drops unchanged findings
+func (e *Engine) isDroppedUnchanged(finding *Finding) bool {
+	return e.dropUnchanged && finding.BaselineState == BaselineUnchanged
+}
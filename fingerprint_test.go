@@ -0,0 +1,167 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/vulnerability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// locationRuleMock returns one finding in each file analyzed, with the path of the file as location
+type locationRuleMock struct {
+	Metadata
+	codeSample string
+}
+
+func (r *locationRuleMock) Run(path string) ([]Finding, error) {
+	return r.RunFile(NewFile(path, nil))
+}
+
+func (r *locationRuleMock) RunFile(file *File) ([]Finding, error) {
+	return []Finding{{
+		ID:             r.ID,
+		CodeSample:     r.codeSample,
+		SourceLocation: Location{Filename: file.Path, Line: 1, Column: 1},
+	}}, nil
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Run("Should ignore changes of whitespaces in the code sample", func(t *testing.T) {
+		assert.Equal(t,
+			Fingerprint("HS-JAVA-1", "A.java", "call( a,\tb )"),
+			Fingerprint("HS-JAVA-1", "A.java", "  call(  a, b )  "),
+		)
+	})
+
+	t.Run("Should normalize the path", func(t *testing.T) {
+		assert.Equal(t,
+			Fingerprint("HS-JAVA-1", "src/main/A.java", "call(a)"),
+			Fingerprint("HS-JAVA-1", "./"+filepath.Join("src", "test", "..", "main", "A.java"), "call(a)"),
+		)
+	})
+
+	t.Run("Should return different fingerprints for different rules, files and code samples", func(t *testing.T) {
+		fingerprint := Fingerprint("HS-JAVA-1", "A.java", "call(a)")
+
+		assert.Len(t, fingerprint, 64)
+		assert.NotEqual(t, fingerprint, Fingerprint("HS-JAVA-2", "A.java", "call(a)"))
+		assert.NotEqual(t, fingerprint, Fingerprint("HS-JAVA-1", "B.java", "call(a)"))
+		assert.NotEqual(t, fingerprint, Fingerprint("HS-JAVA-1", "A.java", "call(b)"))
+		assert.NotEqual(t, Fingerprint("a", "bc", ""), Fingerprint("ab", "c", ""))
+	})
+}
+
+func TestEngineRunFingerprints(t *testing.T) {
+	t.Run("Should set the fingerprint from the path relative to the project", func(t *testing.T) {
+		rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+		findings, err := NewEngine(1, ".go").Run(context.Background(), newTestProject(t, 1), rule)
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+
+		assert.Equal(t, Fingerprint("HS-TEST-1", "file0.go", "call(a)"), findings[0].Fingerprint)
+		assert.Empty(t, findings[0].Acceptance)
+	})
+
+	t.Run("Should keep the fingerprint set by the rule", func(t *testing.T) {
+		rule := newRuleMock([]Finding{{ID: "HS-TEST-1", Fingerprint: "custom"}}, nil)
+
+		findings, err := NewEngine(1, ".go").Run(context.Background(), newTestProject(t, 1), rule)
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+
+		assert.Equal(t, "custom", findings[0].Fingerprint)
+	})
+}
+
+// nolint:funlen // table of accepted hashes options
+func TestEngineRunAcceptedHashes(t *testing.T) {
+	falsePositive := Fingerprint("HS-TEST-1", "file0.go", "call(a)")
+	riskAccepted := Fingerprint("HS-TEST-1", "file1.go", "call(a)")
+
+	testcases := []struct {
+		name     string
+		options  []Option
+		expected map[string]vulnerability.Type
+	}{
+		{
+			name:    "Should mark the findings with accepted hashes",
+			options: []Option{WithFalsePositiveHashes(falsePositive), WithRiskAcceptHashes(" " + riskAccepted + " ")},
+			expected: map[string]vulnerability.Type{
+				"file0.go": vulnerability.FalsePositive,
+				"file1.go": vulnerability.RiskAccepted,
+				"file2.go": "",
+			},
+		},
+		{
+			name: "Should drop the findings with accepted hashes with WithoutAcceptedFindings",
+			options: []Option{
+				WithFalsePositiveHashes(falsePositive), WithRiskAcceptHashes(riskAccepted), WithoutAcceptedFindings(),
+			},
+			expected: map[string]vulnerability.Type{"file2.go": ""},
+		},
+		{
+			name:    "Should keep all findings when there are no accepted hashes",
+			options: []Option{WithoutAcceptedFindings()},
+			expected: map[string]vulnerability.Type{
+				"file0.go": "",
+				"file1.go": "",
+				"file2.go": "",
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+			findings, err := NewEngineWithOptions(2, []string{".go"}, tt.options...).
+				Run(context.Background(), newTestProject(t, 3), rule)
+			require.NoError(t, err)
+
+			acceptances := map[string]vulnerability.Type{}
+			for _, finding := range findings {
+				acceptances[filepath.Base(finding.SourceLocation.Filename)] = finding.Acceptance
+			}
+
+			assert.Equal(t, tt.expected, acceptances)
+		})
+	}
+}
+
+func TestRelativeFindingPath(t *testing.T) {
+	project := filepath.Join("home", "project")
+
+	t.Run("Should return the path relative to the project", func(t *testing.T) {
+		assert.Equal(t, filepath.Join("src", "a.go"), relativeFindingPath(project, filepath.Join(project, "src", "a.go")))
+	})
+
+	t.Run("Should return the file name when the project path is the file", func(t *testing.T) {
+		path := filepath.Join(project, "a.go")
+
+		assert.Equal(t, "a.go", relativeFindingPath(path, path))
+	})
+
+	t.Run("Should keep paths outside of the project", func(t *testing.T) {
+		path := filepath.Join("home", "other", "a.go")
+
+		assert.Equal(t, path, relativeFindingPath(project, path))
+	})
+}
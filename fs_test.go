@@ -0,0 +1,125 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findingsFilenames returns the sorted file names of the findings
+func findingsFilenames(findings []Finding) []string {
+	filenames := make([]string, 0, len(findings))
+
+	for index := range findings {
+		filenames = append(filenames, findings[index].SourceLocation.Filename)
+	}
+
+	sort.Strings(filenames)
+
+	return filenames
+}
+
+// errorFS fails to open the file with the name, and opens any other file from its file system
+type errorFS struct {
+	fs.FS
+	name string
+}
+
+func (f *errorFS) Open(name string) (fs.File, error) {
+	if name == f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("test error")}
+	}
+
+	return f.FS.Open(name)
+}
+
+func TestEngineRunFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":               {Data: []byte("dist/\n")},
+		"main.go":                  {Data: []byte("package main")},
+		"internal/handler.go":      {Data: []byte("package internal")},
+		"internal/handler_test.go": {Data: []byte("package internal")},
+		"dist/bundle.go":           {Data: []byte("package dist")},
+		".git/hooks/hook.go":       {Data: []byte("package hooks")},
+		"README.md":                {Data: []byte("# README")},
+		"logo.go":                  {Data: []byte("\x7fELF\x02\x01\x00\x00")},
+	}
+	rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+	t.Run("Should analyze the files of the file system", func(t *testing.T) {
+		findings, err := NewEngine(2, ".go").RunFS(context.Background(), fsys, rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"dist/bundle.go", "internal/handler.go", "internal/handler_test.go", "main.go",
+		}, findingsFilenames(findings))
+
+		for index := range findings {
+			assert.Equal(t, Fingerprint("HS-TEST-1", findings[index].SourceLocation.Filename, "call(a)"),
+				findings[index].Fingerprint)
+		}
+	})
+
+	t.Run("Should read the .gitignore files from the file system", func(t *testing.T) {
+		findings, err := NewEngineWithOptions(2, []string{".go"}, WithGitIgnore(), WithIgnorePatterns("**/*_test.go")).
+			RunFS(context.Background(), fsys, rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"internal/handler.go", "main.go"}, findingsFilenames(findings))
+	})
+
+	t.Run("Should return the errors of files that can't be read", func(t *testing.T) {
+		result, err := NewEngineWithOptions(2, []string{".go"}, WithErrorPolicy(ContinueOnError)).
+			ScanFS(context.Background(), &errorFS{FS: fsys, name: "main.go"}, rule)
+		require.NoError(t, err)
+
+		assert.Len(t, result.Findings, 3)
+
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "main.go", result.Errors[0].Path)
+		}
+	})
+}
+
+func TestEngineRunSingleFile(t *testing.T) {
+	t.Run("Should analyze only the file when the project path is a file", func(t *testing.T) {
+		projectPath := newTestProject(t, 2)
+		rule := &locationRuleMock{Metadata: Metadata{ID: "HS-TEST-1"}, codeSample: "call(a)"}
+
+		findings, err := NewEngine(1, ".go").Run(context.Background(), filepath.Join(projectPath, "file1.go"), rule)
+		require.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, filepath.Join(projectPath, "file1.go"), findings[0].SourceLocation.Filename)
+			assert.Equal(t, Fingerprint("HS-TEST-1", "file1.go", "call(a)"), findings[0].Fingerprint)
+		}
+	})
+
+	t.Run("Should return error when the project path doesn't exist", func(t *testing.T) {
+		_, err := NewEngine(1, ".go").Run(context.Background(), filepath.Join(t.TempDir(), "missing"),
+			newRuleMock([]Finding{{}}, nil))
+
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
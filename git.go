@@ -0,0 +1,211 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxDiffLineSize is the maximum size of a line of the diff output, long lines are common in minified files
+const maxDiffLineSize = 16 * 1024 * 1024
+
+// hunkHeaderRegexp matches the header of a hunk of a unified diff, capturing the start and the optional length of the
+// lines of the new file
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// GitChangedFiles returns the files changed between the base and head revisions of the git repository in repoPath,
+// with the ranges of their added or modified lines. When head is empty, the base revision is compared with the
+// working tree. The paths are relative to repoPath, and only the files inside it are returned, removed files are
+// ignored. It runs the git binary found in the PATH
+func GitChangedFiles(ctx context.Context, repoPath, base, head string) ([]ChangedFile, error) {
+	if err := validateRevisions(base, head); err != nil {
+		return nil, err
+	}
+
+	output, err := runGitDiff(ctx, repoPath, gitDiffArgs(base, head))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseGitDiff(bytes.NewReader(output))
+}
+
+// validateRevisions checks that the revisions can't be used as options of the git command
+func validateRevisions(revisions ...string) error {
+	for _, revision := range revisions {
+		if strings.HasPrefix(revision, "-") {
+			return fmt.Errorf("invalid git revision %q", revision)
+		}
+	}
+
+	return nil
+}
+
+// gitDiffArgs returns the arguments of git diff to compare the revisions, the empty ones are not added
+func gitDiffArgs(revisions ...string) []string {
+	args := []string{
+		"diff", "--unified=0", "--no-color", "--no-ext-diff", "--relative", "--diff-filter=d",
+		"--src-prefix=a/", "--dst-prefix=b/",
+	}
+
+	for _, revision := range revisions {
+		if revision != "" {
+			args = append(args, revision)
+		}
+	}
+
+	return append(args, "--")
+}
+
+// runGitDiff runs git diff with the arguments in the repository path, returning its output
+func runGitDiff(ctx context.Context, repoPath string, args []string) ([]byte, error) {
+	// nolint:gosec // the revisions are validated by GitChangedFiles, so they can't be used as options of git
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
+
+// gitDiffParser holds the changed files parsed from the output of git diff, the index of the file of the current
+// diff, which is -1 when the diff has no new version of the file, and if the headers of the diff are being parsed
+type gitDiffParser struct {
+	changes  []ChangedFile
+	current  int
+	inHeader bool
+}
+
+// parseGitDiff parses the output of git diff with zero lines of context, returning the changed files and the ranges
+// of the lines of each hunk in the new version of the file. Files without a new version, like the removed ones, and
+// binary files are not returned. The headers of each file are only parsed before its first hunk, since the changed
+// lines can look like headers
+func parseGitDiff(r io.Reader) ([]ChangedFile, error) {
+	parser := &gitDiffParser{current: -1}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxDiffLineSize)
+
+	for scanner.Scan() {
+		if err := parser.parseLine(scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.changes, scanner.Err()
+}
+
+// parseLine parses a line of the diff, which can start the diff of a file, be the header with the path of the new
+// file or be the header of a hunk. All other lines are ignored
+func (p *gitDiffParser) parseLine(line string) error {
+	switch {
+	case strings.HasPrefix(line, "diff "):
+		p.current, p.inHeader = -1, true
+	case p.inHeader && strings.HasPrefix(line, "+++ "):
+		return p.parseNewPath(strings.TrimPrefix(line, "+++ "))
+	case strings.HasPrefix(line, "@@ "):
+		p.parseHunk(line)
+	}
+
+	return nil
+}
+
+// parseNewPath adds the file with the path of the new version of the file to the changed files
+func (p *gitDiffParser) parseNewPath(path string) error {
+	path, ok, err := parseDiffPath(path)
+	if err != nil || !ok {
+		return err
+	}
+
+	p.changes = append(p.changes, ChangedFile{Path: path})
+	p.current = len(p.changes) - 1
+
+	return nil
+}
+
+// parseHunk adds the range of lines of the hunk to the current file, ending its headers
+func (p *gitDiffParser) parseHunk(line string) {
+	if p.current < 0 {
+		return
+	}
+
+	p.inHeader = false
+
+	if lineRange, ok := parseHunkHeader(line); ok {
+		p.changes[p.current].Lines = append(p.changes[p.current].Lines, lineRange)
+	}
+}
+
+// parseDiffPath parses the path of the new file of a diff, which has the "b/" prefix and can be quoted by git when it
+// contains special characters. It returns false when the file has no new version
+func parseDiffPath(path string) (string, bool, error) {
+	if path == "/dev/null" {
+		return "", false, nil
+	}
+
+	if strings.HasPrefix(path, `"`) {
+		unquoted, err := strconv.Unquote(path)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid path in git diff %s: %w", path, err)
+		}
+
+		path = unquoted
+	}
+
+	return strings.TrimPrefix(path, "b/"), true, nil
+}
+
+// parseHunkHeader returns the range of lines of a hunk in the new file, it returns false for hunks that only remove
+// lines
+func parseHunkHeader(line string) (LineRange, bool) {
+	matches := hunkHeaderRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return LineRange{}, false
+	}
+
+	start, _ := strconv.Atoi(matches[1])
+
+	length := parseHunkLength(matches[2])
+	if length == 0 {
+		return LineRange{}, false
+	}
+
+	return LineRange{Start: start, End: start + length - 1}, true
+}
+
+// parseHunkLength parses the optional length of the lines of a hunk, which is 1 when it's omitted
+func parseHunkLength(value string) int {
+	if value == "" {
+		return 1
+	}
+
+	length, _ := strconv.Atoi(value)
+
+	return length
+}
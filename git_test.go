@@ -0,0 +1,148 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGitDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ func main() {
++	call(a)
++++ not a header
+@@ -10 +12 @@ func main() {
+-	old()
++	call(b)
+@@ -20,3 +23,0 @@ func main() {
+-	removed()
+-	removed()
+-	removed()
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+diff --git a/only_removed.go b/only_removed.go
+--- a/only_removed.go
++++ b/only_removed.go
+@@ -1 +0,0 @@
+-package main
+diff --git "a/with\ttab.go" "b/with\ttab.go"
+new file mode 100644
+--- /dev/null
++++ "b/with\ttab.go"
+@@ -0,0 +1,3 @@
++package main
++
++func main() {}
+`
+
+func TestParseGitDiff(t *testing.T) {
+	t.Run("Should parse the changed files and the ranges of the added lines", func(t *testing.T) {
+		changes, err := parseGitDiff(strings.NewReader(testGitDiff))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []ChangedFile{
+			{Path: "main.go", Lines: []LineRange{{Start: 4, End: 5}, {Start: 12, End: 12}}},
+			{Path: "only_removed.go"},
+			{Path: "with\ttab.go", Lines: []LineRange{{Start: 1, End: 3}}},
+		}, changes)
+	})
+
+	t.Run("Should return error when a quoted path is invalid", func(t *testing.T) {
+		_, err := parseGitDiff(strings.NewReader("diff --git a/a b/a\n+++ \"b/a\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+// runGit runs the git command in the directory, failing the test on errors
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+	}, args...)...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoPath := newTestProjectWithFiles(t, map[string]string{
+		"main.go":     "package main\n\nfunc main() {\n}\n",
+		"removed.go":  "package main\n",
+		"sub/sub.go":  "package sub\n",
+		"sub/keep.go": "package sub\n",
+	})
+
+	runGit(t, repoPath, "init", "--quiet")
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "--quiet", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "main.go"),
+		[]byte("package main\n\nfunc main() {\n\tcall(a)\n}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "sub", "sub.go"),
+		[]byte("package sub\n\nvar a = 1\n"), 0o600))
+	require.NoError(t, os.Remove(filepath.Join(repoPath, "removed.go")))
+
+	t.Run("Should return the files changed in the working tree", func(t *testing.T) {
+		changes, err := GitChangedFiles(context.Background(), repoPath, "HEAD", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []ChangedFile{
+			{Path: "main.go", Lines: []LineRange{{Start: 4, End: 4}}},
+			{Path: "sub/sub.go", Lines: []LineRange{{Start: 2, End: 3}}},
+		}, changes)
+	})
+
+	t.Run("Should return the paths relative to a subdirectory of the repository", func(t *testing.T) {
+		changes, err := GitChangedFiles(context.Background(), filepath.Join(repoPath, "sub"), "HEAD", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []ChangedFile{{Path: "sub.go", Lines: []LineRange{{Start: 2, End: 3}}}}, changes)
+	})
+
+	t.Run("Should return the files changed between two revisions", func(t *testing.T) {
+		runGit(t, repoPath, "add", "-A")
+		runGit(t, repoPath, "commit", "--quiet", "-m", "change")
+
+		changes, err := GitChangedFiles(context.Background(), repoPath, "HEAD~1", "HEAD")
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 2)
+	})
+
+	t.Run("Should return error for invalid revisions", func(t *testing.T) {
+		_, err := GitChangedFiles(context.Background(), repoPath, "--output=/tmp/file", "")
+		assert.Error(t, err)
+
+		_, err = GitChangedFiles(context.Background(), repoPath, "unknown-revision", "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// gitIgnore represents a parsed .gitignore file
+type gitIgnore struct {
+	dir      string // dir holds the directory of the file relative to the project root, "." for the root
+	patterns []gitIgnorePattern
+}
+
+// gitIgnorePattern represents a single line of a .gitignore file converted into a doublestar pattern
+type gitIgnorePattern struct {
+	glob    string
+	negate  bool // negate is true for patterns starting with "!", which include again a path ignored before
+	dirOnly bool // dirOnly is true for patterns ending with "/", which only match directories
+}
+
+// parseGitIgnore parses the content of the .gitignore file found in the directory. Blank lines, comments and
+// invalid patterns are ignored
+func parseGitIgnore(dir string, content []byte) *gitIgnore {
+	file := &gitIgnore{dir: dir}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if pattern, ok := parseGitIgnorePattern(scanner.Text()); ok {
+			file.patterns = append(file.patterns, pattern)
+		}
+	}
+
+	return file
+}
+
+// parseGitIgnorePattern converts a .gitignore line into a doublestar pattern. Patterns without a slash, other than a
+// trailing one, match at any level below the .gitignore directory, others are relative to it
+func parseGitIgnorePattern(line string) (pattern gitIgnorePattern, ok bool) {
+	line = trimGitIgnoreSpaces(strings.TrimSuffix(line, "\r"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern, false
+	}
+
+	line, pattern.negate = trimGitIgnoreNegation(line)
+
+	pattern.dirOnly = strings.HasSuffix(line, "/")
+	line = strings.TrimSuffix(line, "/")
+	pattern.glob = gitIgnoreGlob(line)
+
+	return pattern, line != "" && doublestar.ValidatePattern(pattern.glob)
+}
+
+// trimGitIgnoreNegation removes the "!" of negated patterns, returning if the pattern is negated, and the backslash of
+// patterns starting with an escaped "!" or "#"
+func trimGitIgnoreNegation(line string) (string, bool) {
+	if strings.HasPrefix(line, "!") {
+		return line[1:], true
+	}
+
+	if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		return line[1:], false
+	}
+
+	return line, false
+}
+
+// gitIgnoreGlob converts the pattern into a glob relative to the .gitignore directory, patterns without a slash match
+// at any level below it
+func gitIgnoreGlob(line string) string {
+	glob := escapeGitIgnoreGlob(line)
+	if strings.Contains(line, "/") {
+		return strings.TrimPrefix(glob, "/")
+	}
+
+	return "**/" + glob
+}
+
+// trimGitIgnoreSpaces removes the trailing spaces of a line, unless they are escaped with a backslash
+func trimGitIgnoreSpaces(line string) string {
+	trimmed := strings.TrimRight(line, " ")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		return trimmed + " "
+	}
+
+	return trimmed
+}
+
+// escapeGitIgnoreGlob escapes the characters that have a special meaning for doublestar, but not for git
+func escapeGitIgnoreGlob(glob string) string {
+	return strings.NewReplacer("{", `\{`, "}", `\}`).Replace(glob)
+}
+
+// match checks if the path relative to the project root matches any of the patterns, in this case ignored informs
+// if the last pattern that matched ignores the path or includes it again
+func (g *gitIgnore) match(relativePath string, isDir bool) (matched, ignored bool) {
+	if g.dir != "." {
+		relativePath = strings.TrimPrefix(relativePath, g.dir+"/")
+	}
+
+	for _, pattern := range g.patterns {
+		if pattern.matches(relativePath, isDir) {
+			matched, ignored = true, !pattern.negate
+		}
+	}
+
+	return matched, ignored
+}
+
+// matches checks if the path relative to the .gitignore directory matches the pattern, patterns ending with "/" only
+// match directories
+func (p gitIgnorePattern) matches(relativePath string, isDir bool) bool {
+	return (isDir || !p.dirOnly) && doublestar.MatchUnvalidated(p.glob, relativePath)
+}
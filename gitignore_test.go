@@ -0,0 +1,124 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitIgnoreMatch(t *testing.T) {
+	content := []byte(`
+# dependencies
+node_modules/
+*.log
+!important.log
+/build
+docs/*.md
+\#notes
+trailing   
+`)
+
+	testCases := []struct {
+		name            string
+		dir             string
+		path            string
+		isDir           bool
+		expectedMatch   bool
+		expectedIgnored bool
+	}{
+		{
+			name:            "Should ignore directory pattern at any level",
+			dir:             ".",
+			path:            "web/node_modules",
+			isDir:           true,
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+		{
+			name:          "Should not match directory only pattern with a file",
+			dir:           ".",
+			path:          "web/node_modules",
+			isDir:         false,
+			expectedMatch: false,
+		},
+		{
+			name:            "Should ignore files matching a pattern without slash at any level",
+			dir:             ".",
+			path:            "logs/debug.log",
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+		{
+			name:            "Should include again files matching a negated pattern",
+			dir:             ".",
+			path:            "logs/important.log",
+			expectedMatch:   true,
+			expectedIgnored: false,
+		},
+		{
+			name:            "Should ignore anchored pattern only relative to the .gitignore directory",
+			dir:             ".",
+			path:            "build",
+			isDir:           true,
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+		{
+			name:          "Should not ignore anchored pattern in sub directories",
+			dir:           ".",
+			path:          "src/build",
+			isDir:         true,
+			expectedMatch: false,
+		},
+		{
+			name:            "Should match patterns relative to the .gitignore directory",
+			dir:             "project",
+			path:            "project/docs/README.md",
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+		{
+			name:          "Should not match files of nested directories with single star",
+			dir:           ".",
+			path:          "docs/api/README.md",
+			expectedMatch: false,
+		},
+		{
+			name:            "Should match escaped comment character",
+			dir:             ".",
+			path:            "#notes",
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+		{
+			name:            "Should ignore trailing spaces",
+			dir:             ".",
+			path:            "trailing",
+			expectedMatch:   true,
+			expectedIgnored: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			matched, ignored := parseGitIgnore(testCase.dir, content).match(testCase.path, testCase.isDir)
+
+			assert.Equal(t, testCase.expectedMatch, matched)
+			assert.Equal(t, testCase.expectedIgnored, ignored)
+		})
+	}
+}
@@ -4,7 +4,9 @@ go 1.14
 
 require (
 	github.com/ZupIT/horusec-devkit v1.0.24
+	github.com/bmatcuk/doublestar/v4 v4.10.0
 	github.com/panjf2000/ants/v2 v2.4.8
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.0
 	github.com/stretchr/testify v1.7.1
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	gopkg.in/yaml.v3 v3.0.1
 )
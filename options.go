@@ -0,0 +1,130 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import "strings"
+
+// Option customizes the engine behavior, it should be passed to NewEngineWithOptions
+type Option func(e *Engine)
+
+// WithIgnorePatterns sets doublestar-style glob patterns (e.g. **/node_modules/**, **/*_test.go) of files and
+// directories that should not be analyzed. The patterns are matched against the path relative to the project path,
+// always using forward slashes as separator. Directories that match are not walked
+func WithIgnorePatterns(patterns ...string) Option {
+	return func(e *Engine) {
+		e.ignorePatterns = append(e.ignorePatterns, patterns...)
+	}
+}
+
+// WithIncludePatterns sets doublestar-style glob patterns of the files that should be analyzed. When set, only files
+// that match at least one of them are analyzed, the ignore patterns and extensions are still applied
+func WithIncludePatterns(patterns ...string) Option {
+	return func(e *Engine) {
+		e.includePatterns = append(e.includePatterns, patterns...)
+	}
+}
+
+// WithGitIgnore makes the engine honor the .gitignore files found while walking the project, files and directories
+// ignored by them are not analyzed
+func WithGitIgnore() Option {
+	return func(e *Engine) {
+		e.gitIgnore = true
+	}
+}
+
+// WithErrorPolicy sets how the engine handles errors of files and rules during the analysis, the default is FailFast
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(e *Engine) {
+		e.errorPolicy = policy
+	}
+}
+
+// WithBinarySniffSize sets the number of bytes from the beginning of each file that are inspected to detect binary
+// files, which are skipped before running any rule. The default is DefaultBinarySniffSize, and zero or a negative
+// size inspects the whole file
+func WithBinarySniffSize(size int) Option {
+	return func(e *Engine) {
+		e.sniffSize = size
+	}
+}
+
+// WithoutSuppressedFindings drops the findings suppressed by inline annotations, like "// horusec-ignore". By default,
+// they are kept with the Finding.Suppression set, so the suppressions can be audited
+func WithoutSuppressedFindings() Option {
+	return func(e *Engine) {
+		e.dropSuppressed = true
+	}
+}
+
+// WithFalsePositiveHashes sets the fingerprints of the findings that were reviewed as false positives, these findings
+// have Finding.Acceptance set to vulnerability.FalsePositive
+func WithFalsePositiveHashes(hashes ...string) Option {
+	return func(e *Engine) {
+		e.falsePositiveHashes = addHashes(e.falsePositiveHashes, hashes)
+	}
+}
+
+// WithRiskAcceptHashes sets the fingerprints of the findings whose risk was accepted, these findings have
+// Finding.Acceptance set to vulnerability.RiskAccepted
+func WithRiskAcceptHashes(hashes ...string) Option {
+	return func(e *Engine) {
+		e.riskAcceptHashes = addHashes(e.riskAcceptHashes, hashes)
+	}
+}
+
+// WithoutAcceptedFindings drops the findings accepted as false positives or risks. By default, they are kept with the
+// Finding.Acceptance set
+func WithoutAcceptedFindings() Option {
+	return func(e *Engine) {
+		e.dropAccepted = true
+	}
+}
+
+// addHashes adds the hashes into the set, creating it if it's nil
+func addHashes(set map[string]bool, hashes []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool, len(hashes))
+	}
+
+	for _, hash := range hashes {
+		set[strings.TrimSpace(hash)] = true
+	}
+
+	return set
+}
+
+// WithBaseline compares the findings with the baseline of a previous analysis, setting Finding.BaselineState of each
+// finding as new or unchanged, and Scan also returns the findings of the baseline that were not found as fixed
+func WithBaseline(baseline *Baseline) Option {
+	return func(e *Engine) {
+		e.baseline = baseline
+	}
+}
+
+// WithoutUnchangedFindings drops the findings that are in the baseline informed through WithBaseline, so only the new
+// findings are reported
+func WithoutUnchangedFindings() Option {
+	return func(e *Engine) {
+		e.dropUnchanged = true
+	}
+}
+
+// WithChangedLinesOnly makes RunChanges and ScanChanges report only the findings on the added or modified lines of
+// the changed files, findings that refer to the whole file, without a line, are still reported
+func WithChangedLinesOnly() Option {
+	return func(e *Engine) {
+		e.changedLinesOnly = true
+	}
+}
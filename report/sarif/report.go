@@ -0,0 +1,413 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/severities"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+const (
+	// DriverName is the name of the tool driver of the logs created by this package
+	DriverName = "Horusec Engine"
+
+	// DriverInformationURI is the URI with information about the tool driver
+	DriverInformationURI = "https://github.com/ZupIT/horusec-engine"
+
+	// FingerprintKey is the key of the partial fingerprint of each result, which holds the finding fingerprint that
+	// identifies the same result across different analyses even if its lines change
+	FingerprintKey = "horusecFingerprint/v1"
+
+	cweTaxonomyName           = "CWE"
+	cweTaxonomyOrganization   = "MITRE"
+	cweTaxonomyInformationURI = "https://cwe.mitre.org/"
+	cweTaxonomyDescription    = "The MITRE Common Weakness Enumeration"
+
+	columnKindUnicodeCodePoints = "unicodeCodePoints"
+
+	suppressionKindInSource = "inSource"
+)
+
+// Levels of the results, which are derived from the severities of the findings and rules
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// securitySeverities maps each severity to the numeric score used by code scanning tools to rank the results, the
+// scores are inside the range of each severity in the CVSS qualitative scale
+var securitySeverities = map[severities.Severity]string{
+	severities.Critical: "9.5",
+	severities.High:     "8.0",
+	severities.Medium:   "5.5",
+	severities.Low:      "2.0",
+}
+
+// Write converts the findings and the metadata of the rules that ran into a SARIF log, and writes it as indented
+// JSON. See NewLog for details of the conversion
+func Write(w io.Writer, findings []engine.Finding, rules []engine.Metadata) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(NewLog(findings, rules))
+}
+
+// NewLog converts the findings and the metadata of the rules that ran into a SARIF log with a single run.
+// All rules are added to the tool driver, even the ones without findings, as well as any other rule that reported a
+// finding, whose metadata is taken from the finding. The CWEs of the rules are added to the CWE taxonomy of the run
+// and related to the rules. Findings without a line, which are related to the whole file, don't have a region
+func NewLog(findings []engine.Finding, rules []engine.Metadata) *Log {
+	builder := newRunBuilder()
+
+	for index := range rules {
+		builder.addRule(&rules[index])
+	}
+
+	for index := range findings {
+		builder.addResult(&findings[index])
+	}
+
+	return &Log{
+		Schema:  Schema,
+		Version: Version,
+		Runs:    []*Run{builder.build()},
+	}
+}
+
+// runBuilder accumulates the rules, taxa and results of a run, keeping the index of each rule and taxon by its ID
+type runBuilder struct {
+	rules      []*ReportingDescriptor
+	rulesIndex map[string]int
+	taxa       []*ReportingDescriptor
+	taxaIndex  map[string]int
+	results    []*Result
+}
+
+func newRunBuilder() *runBuilder {
+	return &runBuilder{
+		rulesIndex: map[string]int{},
+		taxaIndex:  map[string]int{},
+		results:    []*Result{},
+	}
+}
+
+// build creates the run, the CWE taxonomy is only added when some rule is related to a CWE
+func (b *runBuilder) build() *Run {
+	run := &Run{
+		Tool:       Tool{Driver: ToolComponent{Name: DriverName, InformationURI: DriverInformationURI, Rules: b.rules}},
+		Results:    b.results,
+		ColumnKind: columnKindUnicodeCodePoints,
+	}
+
+	if len(b.taxa) > 0 {
+		run.Tool.Driver.SupportedTaxonomies = []*ToolComponentReference{{Name: cweTaxonomyName}}
+		run.Taxonomies = []*ToolComponent{b.newCWETaxonomy()}
+	}
+
+	return run
+}
+
+// newCWETaxonomy creates the CWE taxonomy with the CWEs related to the rules of the run
+func (b *runBuilder) newCWETaxonomy() *ToolComponent {
+	return &ToolComponent{
+		Name:             cweTaxonomyName,
+		Organization:     cweTaxonomyOrganization,
+		InformationURI:   cweTaxonomyInformationURI,
+		ShortDescription: &Message{Text: cweTaxonomyDescription},
+		Taxa:             b.taxa,
+	}
+}
+
+// addRule adds the rule to the tool driver if there isn't a rule with the same ID yet, and returns its index
+func (b *runBuilder) addRule(metadata *engine.Metadata) int {
+	if index, ok := b.rulesIndex[metadata.ID]; ok {
+		return index
+	}
+
+	rule := newReportingDescriptor(metadata)
+	for _, cwe := range metadata.CWEs {
+		rule.Relationships = append(rule.Relationships, b.newCWERelationship(cwe))
+	}
+
+	b.rulesIndex[metadata.ID] = len(b.rules)
+	b.rules = append(b.rules, rule)
+
+	return b.rulesIndex[metadata.ID]
+}
+
+// newReportingDescriptor creates the rule of the tool driver from the metadata, without its CWE relationships
+func newReportingDescriptor(metadata *engine.Metadata) *ReportingDescriptor {
+	return &ReportingDescriptor{
+		ID:                   metadata.ID,
+		Name:                 metadata.Name,
+		ShortDescription:     newMessage(metadata.Name),
+		FullDescription:      newMessage(metadata.Description),
+		Help:                 newMessage(metadata.Mitigation),
+		HelpURI:              absoluteURI(metadata.Reference),
+		DefaultConfiguration: &ReportingConfiguration{Level: Level(metadata.Severity)},
+		Properties:           newRuleProperties(metadata),
+	}
+}
+
+// newCWERelationship relates a rule with the CWE, adding the CWE to the taxonomy if it's not there yet
+func (b *runBuilder) newCWERelationship(cwe string) *ReportingDescriptorRelationship {
+	id := normalizeCWE(cwe)
+	if _, ok := b.taxaIndex[id]; !ok {
+		b.addTaxon(id)
+	}
+
+	return &ReportingDescriptorRelationship{
+		Target: ReportingDescriptorReference{
+			ID:            id,
+			ToolComponent: &ToolComponentReference{Name: cweTaxonomyName},
+		},
+		Kinds: []string{"superset"},
+	}
+}
+
+// addTaxon adds the CWE with the normalized id to the taxonomy
+func (b *runBuilder) addTaxon(id string) {
+	b.taxaIndex[id] = len(b.taxa)
+	b.taxa = append(b.taxa, &ReportingDescriptor{
+		ID:      id,
+		Name:    "CWE-" + id,
+		HelpURI: "https://cwe.mitre.org/data/definitions/" + id + ".html",
+	})
+}
+
+// addResult adds the finding as a result of the run, the rule of the finding is added to the tool driver when it
+// wasn't informed, using the finding metadata if it's available
+func (b *runBuilder) addResult(finding *engine.Finding) {
+	b.results = append(b.results, newResult(finding, b.addRule(metadataOf(finding))))
+}
+
+// metadataOf returns the metadata of the finding, or creates it from the finding fields when it wasn't informed
+func metadataOf(finding *engine.Finding) *engine.Metadata {
+	if finding.Metadata != nil {
+		return finding.Metadata
+	}
+
+	return &engine.Metadata{
+		ID:          finding.ID,
+		Name:        finding.Name,
+		Description: finding.Description,
+		Severity:    finding.Severity,
+		Confidence:  finding.Confidence,
+	}
+}
+
+// newResult creates the result of the finding, related to the rule with the index in the tool driver
+func newResult(finding *engine.Finding, ruleIndex int) *Result {
+	return &Result{
+		RuleID:              finding.ID,
+		RuleIndex:           ruleIndex,
+		Level:               Level(finding.Severity),
+		Message:             Message{Text: firstNonEmpty(finding.Description, finding.Name, finding.ID)},
+		Locations:           []*Location{newLocation(finding)},
+		PartialFingerprints: map[string]string{FingerprintKey: getFingerprint(finding)},
+		BaselineState:       string(finding.BaselineState),
+		Suppressions:        newSuppressions(finding),
+		Properties:          newResultProperties(finding),
+	}
+}
+
+// newSuppressions creates the in source suppression of a finding suppressed by an inline annotation, with the
+// location of the annotation
+func newSuppressions(finding *engine.Finding) []*Suppression {
+	if finding.Suppression == nil {
+		return nil
+	}
+
+	return []*Suppression{{
+		Kind:     suppressionKindInSource,
+		Location: newLocation(&engine.Finding{SourceLocation: finding.Suppression.Location}),
+	}}
+}
+
+// newLocation creates the location of the finding, the regions are only set when the finding has a line, so file
+// level findings refer to the whole artifact
+func newLocation(finding *engine.Finding) *Location {
+	source := &finding.SourceLocation
+	location := &Location{PhysicalLocation: PhysicalLocation{ArtifactLocation: ArtifactLocation{
+		URI: fileURI(source.Filename),
+	}}}
+
+	if !source.FileLevel && source.Line > 0 {
+		location.PhysicalLocation.Region = newRegion(source)
+		location.PhysicalLocation.ContextRegion = newContextRegion(finding)
+	}
+
+	return location
+}
+
+// newRegion creates the region of the match of the finding, the end and the byte offsets are only set when they're
+// valid. The region has no snippet, since only the line of the match is known, which is set in the context region
+func newRegion(source *engine.Location) *Region {
+	region := &Region{StartLine: source.Line, StartColumn: source.Column}
+
+	if source.EndLine >= source.Line && source.EndColumn > 0 {
+		region.EndLine, region.EndColumn = source.EndLine, source.EndColumn
+	}
+
+	region.ByteOffset, region.ByteLength = byteRange(source)
+
+	return region
+}
+
+// newContextRegion creates the region of the line of the finding with its code sample as snippet, or nil when the
+// finding has no code sample
+func newContextRegion(finding *engine.Finding) *Region {
+	if finding.CodeSample == "" {
+		return nil
+	}
+
+	return &Region{StartLine: finding.SourceLocation.Line, Snippet: &ArtifactContent{Text: finding.CodeSample}}
+}
+
+// byteRange returns the byte offset and length of the location, or nil when its offsets aren't valid
+func byteRange(source *engine.Location) (offset, length *int) {
+	if source.EndOffset <= source.Offset {
+		return nil, nil
+	}
+
+	start, size := source.Offset, source.EndOffset-source.Offset
+
+	return &start, &size
+}
+
+// newRuleProperties creates the properties of a rule with its tags, security severity, precision and CVEs
+func newRuleProperties(metadata *engine.Metadata) PropertyBag {
+	properties := PropertyBag{"tags": newRuleTags(metadata)}
+
+	if score, ok := securitySeverities[severities.GetSeverityByString(strings.ToUpper(metadata.Severity))]; ok {
+		properties["security-severity"] = score
+	}
+
+	if metadata.Confidence != "" {
+		properties["precision"] = strings.ToLower(metadata.Confidence)
+	}
+
+	if len(metadata.CVEs) > 0 {
+		properties["cves"] = metadata.CVEs
+	}
+
+	return properties
+}
+
+// newRuleTags creates the tags of a rule, marking it as a security rule related to its CWEs
+func newRuleTags(metadata *engine.Metadata) []string {
+	tags := []string{"security"}
+	for _, cwe := range metadata.CWEs {
+		tags = append(tags, "external/cwe/cwe-"+normalizeCWE(cwe))
+	}
+
+	return tags
+}
+
+// newResultProperties creates the properties of a result with the severity and confidence of the finding
+func newResultProperties(finding *engine.Finding) PropertyBag {
+	return PropertyBag{
+		"severity":   finding.Severity,
+		"confidence": finding.Confidence,
+	}
+}
+
+// Level converts a severity into the level of a result. CRITICAL and HIGH are errors, MEDIUM is a warning and any
+// other severity is a note
+func Level(severity string) string {
+	// nolint:exhaustive // all other severities are notes
+	switch severities.GetSeverityByString(strings.ToUpper(severity)) {
+	case severities.Critical, severities.High:
+		return LevelError
+	case severities.Medium:
+		return LevelWarning
+	default:
+		return LevelNote
+	}
+}
+
+// getFingerprint returns the fingerprint set by the engine, or computes it from the finding file name when the
+// finding wasn't reported by the engine
+func getFingerprint(finding *engine.Finding) string {
+	if finding.Fingerprint != "" {
+		return finding.Fingerprint
+	}
+
+	return engine.Fingerprint(finding.ID, finding.SourceLocation.Filename, finding.CodeSample)
+}
+
+// fileURI converts the file path into an URI. Relative paths are kept as relative references using forward slashes,
+// while absolute paths are converted into file URIs
+func fileURI(path string) string {
+	slashPath := filepath.ToSlash(path)
+	if !filepath.IsAbs(path) {
+		return (&url.URL{Path: slashPath}).String()
+	}
+
+	if !strings.HasPrefix(slashPath, "/") {
+		slashPath = "/" + slashPath
+	}
+
+	return (&url.URL{Scheme: "file", Path: slashPath}).String()
+}
+
+// absoluteURI returns the reference if it's an absolute URI, otherwise an empty string, since the help URI of a rule
+// must be absolute
+func absoluteURI(reference string) string {
+	parsed, err := url.Parse(strings.TrimSpace(reference))
+	if err != nil || !parsed.IsAbs() {
+		return ""
+	}
+
+	return parsed.String()
+}
+
+// normalizeCWE returns the number of the CWE, accepting both "CWE-327" and "327" formats
+func normalizeCWE(cwe string) string {
+	cwe = strings.TrimSpace(cwe)
+	if len(cwe) > len("CWE-") && strings.EqualFold(cwe[:len("CWE-")], "CWE-") {
+		return cwe[len("CWE-"):]
+	}
+
+	return cwe
+}
+
+// newMessage creates a message with the text, or nil if the text is empty
+func newMessage(text string) *Message {
+	if text == "" {
+		return nil
+	}
+
+	return &Message{Text: text}
+}
+
+// firstNonEmpty returns the first value that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
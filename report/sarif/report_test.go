@@ -0,0 +1,297 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+const schemaPath = "testdata/sarif-schema-2.1.0.json"
+
+var weakHashMetadata = engine.Metadata{
+	ID:            "HS-JAVA-1",
+	Name:          "Weak hash",
+	Description:   "MD5 is a weak hash algorithm",
+	Severity:      "MEDIUM",
+	Confidence:    "HIGH",
+	CWEs:          []string{"CWE-327", "328"},
+	CVEs:          []string{"CVE-2004-2761"},
+	Mitigation:    "Use SHA-256 or stronger hash algorithms",
+	Reference:     "https://cwe.mitre.org/data/definitions/327.html",
+	SafeExample:   `MessageDigest.getInstance("SHA-256")`,
+	UnsafeExample: `MessageDigest.getInstance("MD5")`,
+}
+
+var hardcodedSecretMetadata = engine.Metadata{
+	ID:          "HS-LEAKS-1",
+	Name:        "Hardcoded secret",
+	Description: "Secrets should not be stored in the source code",
+	Severity:    "CRITICAL",
+	Confidence:  "MEDIUM",
+	CWEs:        []string{"CWE-798"},
+	Reference:   "not an uri",
+}
+
+func newTestFindings() []engine.Finding {
+	return []engine.Finding{
+		{
+			ID:          weakHashMetadata.ID,
+			Name:        weakHashMetadata.Name,
+			Severity:    weakHashMetadata.Severity,
+			Confidence:  weakHashMetadata.Confidence,
+			Description: weakHashMetadata.Description,
+			CodeSample:  `MessageDigest.getInstance("MD5");`,
+			SourceLocation: engine.Location{
+				Filename: filepath.Join("src", "main", "Hash Util.java"),
+				Line:     3, Column: 5, EndLine: 3, EndColumn: 37, Offset: 40, EndOffset: 72,
+			},
+			Metadata:      &weakHashMetadata,
+			BaselineState: engine.BaselineNew,
+		},
+		{
+			ID:             "HS-UNKNOWN-1",
+			Name:           "Rule without metadata",
+			Severity:       "LOW",
+			Confidence:     "LOW",
+			Description:    "The whole file is vulnerable",
+			SourceLocation: engine.Location{Filename: "main.go", FileLevel: true},
+			Suppression: &engine.Suppression{
+				Kind:     engine.SuppressionHorusecIgnore,
+				Location: engine.Location{Filename: "main.go", Line: 1, Column: 1, EndLine: 1, EndColumn: 18},
+			},
+		},
+	}
+}
+
+func validateSchema(t *testing.T, content []byte) error {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft4
+
+	schemaFile, err := os.Open(schemaPath)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = schemaFile.Close()
+	}()
+
+	require.NoError(t, compiler.AddResource(schemaPath, schemaFile))
+
+	schema, err := compiler.Compile(schemaPath)
+	require.NoError(t, err)
+
+	var log interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&log))
+
+	return schema.Validate(log)
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("Should write a log valid against the SARIF 2.1.0 schema", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+
+		err := Write(buffer, newTestFindings(), []engine.Metadata{weakHashMetadata, hardcodedSecretMetadata})
+		assert.NoError(t, err)
+		assert.NoError(t, validateSchema(t, buffer.Bytes()))
+	})
+
+	t.Run("Should write a valid log without findings and rules", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+
+		assert.NoError(t, Write(buffer, nil, nil))
+		assert.NoError(t, validateSchema(t, buffer.Bytes()))
+		assert.Contains(t, buffer.String(), `"results": []`)
+	})
+
+	t.Run("Should fail the schema validation of an invalid log", func(t *testing.T) {
+		assert.Error(t, validateSchema(t, []byte(`{"version": "2.1.0", "runs": [{"results": []}]}`)))
+	})
+}
+
+// nolint:funlen // table of assertions of all fields of the converted rules
+func TestNewLogRules(t *testing.T) {
+	log := NewLog(newTestFindings(), []engine.Metadata{weakHashMetadata, hardcodedSecretMetadata, weakHashMetadata})
+
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+
+	t.Run("Should add the rules that ran and the ones that reported findings only once", func(t *testing.T) {
+		require.Len(t, run.Tool.Driver.Rules, 3)
+		assert.Equal(t, "HS-JAVA-1", run.Tool.Driver.Rules[0].ID)
+		assert.Equal(t, "HS-LEAKS-1", run.Tool.Driver.Rules[1].ID)
+		assert.Equal(t, "HS-UNKNOWN-1", run.Tool.Driver.Rules[2].ID)
+	})
+
+	t.Run("Should convert the rule metadata", func(t *testing.T) {
+		rule := run.Tool.Driver.Rules[0]
+
+		assert.Equal(t, "Weak hash", rule.Name)
+		assert.Equal(t, &Message{Text: "Weak hash"}, rule.ShortDescription)
+		assert.Equal(t, &Message{Text: "MD5 is a weak hash algorithm"}, rule.FullDescription)
+		assert.Equal(t, &Message{Text: "Use SHA-256 or stronger hash algorithms"}, rule.Help)
+		assert.Equal(t, "https://cwe.mitre.org/data/definitions/327.html", rule.HelpURI)
+		assert.Equal(t, &ReportingConfiguration{Level: LevelWarning}, rule.DefaultConfiguration)
+		assert.Equal(t, PropertyBag{
+			"tags":              []string{"security", "external/cwe/cwe-327", "external/cwe/cwe-328"},
+			"security-severity": "5.5",
+			"precision":         "high",
+			"cves":              []string{"CVE-2004-2761"},
+		}, rule.Properties)
+	})
+
+	t.Run("Should ignore references that are not absolute URIs", func(t *testing.T) {
+		assert.Empty(t, run.Tool.Driver.Rules[1].HelpURI)
+		assert.Nil(t, run.Tool.Driver.Rules[1].Help)
+	})
+
+	t.Run("Should relate the rules with the CWE taxonomy", func(t *testing.T) {
+		assert.Equal(t, []*ToolComponentReference{{Name: "CWE"}}, run.Tool.Driver.SupportedTaxonomies)
+		require.Len(t, run.Taxonomies, 1)
+		assert.Equal(t, "CWE", run.Taxonomies[0].Name)
+
+		var taxa []string
+		for _, taxon := range run.Taxonomies[0].Taxa {
+			taxa = append(taxa, taxon.ID)
+		}
+
+		assert.Equal(t, []string{"327", "328", "798"}, taxa)
+		assert.Equal(t, []*ReportingDescriptorRelationship{
+			{
+				Target: ReportingDescriptorReference{ID: "798", ToolComponent: &ToolComponentReference{Name: "CWE"}},
+				Kinds:  []string{"superset"},
+			},
+		}, run.Tool.Driver.Rules[1].Relationships)
+		assert.Empty(t, run.Tool.Driver.Rules[2].Relationships)
+	})
+
+	t.Run("Should not add the CWE taxonomy when no rule has CWEs", func(t *testing.T) {
+		run := NewLog(newTestFindings()[1:], nil).Runs[0]
+
+		assert.Empty(t, run.Taxonomies)
+		assert.Empty(t, run.Tool.Driver.SupportedTaxonomies)
+	})
+}
+
+// nolint:funlen // table of assertions of all fields of the converted results
+func TestNewLogResults(t *testing.T) {
+	run := NewLog(newTestFindings(), []engine.Metadata{hardcodedSecretMetadata}).Runs[0]
+
+	require.Len(t, run.Results, 2)
+
+	t.Run("Should convert the finding with its region and the code sample in the context", func(t *testing.T) {
+		result := run.Results[0]
+		offset, length := 40, 32
+
+		assert.Equal(t, "HS-JAVA-1", result.RuleID)
+		assert.Equal(t, 1, result.RuleIndex)
+		assert.Equal(t, LevelWarning, result.Level)
+		assert.Equal(t, Message{Text: "MD5 is a weak hash algorithm"}, result.Message)
+		assert.Equal(t, "new", result.BaselineState)
+		assert.Equal(t, "unicodeCodePoints", run.ColumnKind)
+		assert.Equal(t, []*Location{{PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: "src/main/Hash%20Util.java"},
+			Region: &Region{
+				StartLine: 3, StartColumn: 5, EndLine: 3, EndColumn: 37,
+				ByteOffset: &offset, ByteLength: &length,
+			},
+			ContextRegion: &Region{StartLine: 3, Snippet: &ArtifactContent{Text: `MessageDigest.getInstance("MD5");`}},
+		}}}, result.Locations)
+	})
+
+	t.Run("Should not set the region of findings of the whole file", func(t *testing.T) {
+		result := run.Results[1]
+
+		assert.Equal(t, 2, result.RuleIndex)
+		assert.Equal(t, LevelNote, result.Level)
+		assert.Equal(t, "main.go", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Nil(t, result.Locations[0].PhysicalLocation.Region)
+	})
+
+	t.Run("Should set the in source suppression of suppressed findings", func(t *testing.T) {
+		assert.Empty(t, run.Results[0].Suppressions)
+		assert.Equal(t, []*Suppression{{
+			Kind: "inSource",
+			Location: &Location{PhysicalLocation: PhysicalLocation{
+				ArtifactLocation: ArtifactLocation{URI: "main.go"},
+				Region:           &Region{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 18},
+			}},
+		}}, run.Results[1].Suppressions)
+	})
+
+	t.Run("Should set a stable partial fingerprint", func(t *testing.T) {
+		fingerprint := run.Results[0].PartialFingerprints[FingerprintKey]
+
+		assert.Len(t, fingerprint, 64)
+		assert.Equal(t, engine.Fingerprint("HS-JAVA-1", "src/main/Hash Util.java", `MessageDigest.getInstance("MD5");`),
+			fingerprint)
+		assert.NotEqual(t, fingerprint, run.Results[1].PartialFingerprints[FingerprintKey])
+	})
+
+	t.Run("Should use the fingerprint set by the engine", func(t *testing.T) {
+		findings := newTestFindings()
+		findings[0].Fingerprint = "fingerprint"
+
+		result := NewLog(findings, nil).Runs[0].Results[0]
+
+		assert.Equal(t, map[string]string{FingerprintKey: "fingerprint"}, result.PartialFingerprints)
+	})
+}
+
+func TestLevel(t *testing.T) {
+	testcases := []struct {
+		severity string
+		expected string
+	}{
+		{severity: "CRITICAL", expected: LevelError},
+		{severity: "HIGH", expected: LevelError},
+		{severity: "medium", expected: LevelWarning},
+		{severity: "LOW", expected: LevelNote},
+		{severity: "INFO", expected: LevelNote},
+		{severity: "UNKNOWN", expected: LevelNote},
+		{severity: "", expected: LevelNote},
+	}
+
+	for _, tt := range testcases {
+		t.Run("Should map the severity "+tt.severity+" into the level "+tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Level(tt.severity))
+		})
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	t.Run("Should keep relative paths as relative references", func(t *testing.T) {
+		assert.Equal(t, "src/a.go", fileURI(filepath.Join("src", "a.go")))
+	})
+
+	t.Run("Should convert absolute paths into file URIs", func(t *testing.T) {
+		path, err := filepath.Abs(filepath.Join("src", "a.go"))
+		require.NoError(t, err)
+
+		assert.Regexp(t, `^file:///.*/src/a\.go$`, fileURI(path))
+	})
+}
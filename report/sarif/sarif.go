@@ -0,0 +1,155 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sarif converts the engine findings into a Static Analysis Results Interchange Format (SARIF) 2.1.0 log,
+// which can be uploaded to code scanning tools. Only the subset of the format used by the engine is represented by
+// the types of this package
+package sarif
+
+const (
+	// Version is the SARIF version of the logs created by this package
+	Version = "2.1.0"
+
+	// Schema is the URI of the JSON schema of the SARIF version of the logs created by this package
+	Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Log is the top-level object of a SARIF file
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []*Run `json:"runs"`
+}
+
+// Run describes a single invocation of the analysis tool and its results
+type Run struct {
+	Tool       Tool             `json:"tool"`
+	Taxonomies []*ToolComponent `json:"taxonomies,omitempty"`
+	Results    []*Result        `json:"results"`
+	ColumnKind string           `json:"columnKind,omitempty"`
+}
+
+// Tool describes the analysis tool that was run
+type Tool struct {
+	Driver ToolComponent `json:"driver"`
+}
+
+// ToolComponent describes the tool driver or a taxonomy, like CWE, used to classify the rules
+type ToolComponent struct {
+	Name                string                    `json:"name"`
+	Version             string                    `json:"version,omitempty"`
+	Organization        string                    `json:"organization,omitempty"`
+	InformationURI      string                    `json:"informationUri,omitempty"`
+	ShortDescription    *Message                  `json:"shortDescription,omitempty"`
+	Rules               []*ReportingDescriptor    `json:"rules,omitempty"`
+	Taxa                []*ReportingDescriptor    `json:"taxa,omitempty"`
+	SupportedTaxonomies []*ToolComponentReference `json:"supportedTaxonomies,omitempty"`
+}
+
+// ToolComponentReference identifies a tool component by its name
+type ToolComponentReference struct {
+	Name string `json:"name"`
+}
+
+// ReportingDescriptor describes a rule of the tool driver or a taxon of a taxonomy
+type ReportingDescriptor struct {
+	ID                   string                             `json:"id"`
+	Name                 string                             `json:"name,omitempty"`
+	ShortDescription     *Message                           `json:"shortDescription,omitempty"`
+	FullDescription      *Message                           `json:"fullDescription,omitempty"`
+	Help                 *Message                           `json:"help,omitempty"`
+	HelpURI              string                             `json:"helpUri,omitempty"`
+	DefaultConfiguration *ReportingConfiguration            `json:"defaultConfiguration,omitempty"`
+	Relationships        []*ReportingDescriptorRelationship `json:"relationships,omitempty"`
+	Properties           PropertyBag                        `json:"properties,omitempty"`
+}
+
+// ReportingConfiguration holds the default level of the results of a rule
+type ReportingConfiguration struct {
+	Level string `json:"level"`
+}
+
+// ReportingDescriptorRelationship relates a rule with a taxon, like the CWE weaknesses that the rule detects
+type ReportingDescriptorRelationship struct {
+	Target ReportingDescriptorReference `json:"target"`
+	Kinds  []string                     `json:"kinds,omitempty"`
+}
+
+// ReportingDescriptorReference identifies a taxon by its ID and the taxonomy that contains it
+type ReportingDescriptorReference struct {
+	ID            string                  `json:"id"`
+	ToolComponent *ToolComponentReference `json:"toolComponent,omitempty"`
+}
+
+// Message is a plain text message
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result represents a single finding of the analysis
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []*Location       `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	BaselineState       string            `json:"baselineState,omitempty"`
+	Suppressions        []*Suppression    `json:"suppressions,omitempty"`
+	Properties          PropertyBag       `json:"properties,omitempty"`
+}
+
+// Suppression represents a request to suppress a result, like an inline annotation in the source code
+type Suppression struct {
+	Kind     string    `json:"kind"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location is the location of a result
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is the file of a result and, when known, the region of the file. The context region is the line
+// of the region, with the code sample of the result as its snippet
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+	ContextRegion    *Region          `json:"contextRegion,omitempty"`
+}
+
+// ArtifactLocation identifies a file by its URI, relative paths are used as relative references
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is the part of a file where a result was found. Columns are counted in unicode code points, which is set
+// in the run columnKind, and the end column points right after the last character of the region
+type Region struct {
+	StartLine   int              `json:"startLine"`
+	StartColumn int              `json:"startColumn,omitempty"`
+	EndLine     int              `json:"endLine,omitempty"`
+	EndColumn   int              `json:"endColumn,omitempty"`
+	ByteOffset  *int             `json:"byteOffset,omitempty"`
+	ByteLength  *int             `json:"byteLength,omitempty"`
+	Snippet     *ArtifactContent `json:"snippet,omitempty"`
+}
+
+// ArtifactContent holds the text of a region
+type ArtifactContent struct {
+	Text string `json:"text"`
+}
+
+// PropertyBag holds additional properties of an object, like tags and the security severity of the rules
+type PropertyBag map[string]interface{}
@@ -0,0 +1,33 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// ScanResult represents the result of an analysis, it contains all findings and the errors of files and rules that
+// failed without stopping the analysis. When the findings are compared with a baseline, Fixed holds the findings of
+// the baseline that were not found
+type ScanResult struct {
+	Findings []Finding
+	Errors   []*ScanError
+	Fixed    []BaselineFinding
+}
+
+// Err returns all errors of the result as ScanErrors, or nil if there is none
+func (r *ScanResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return ScanErrors(r.Errors)
+}
@@ -14,9 +14,27 @@
 
 package engine
 
-// Rule defines a generic rule for any kind of analysis the engine have to execute
+// Rule defines a generic rule for any kind of analysis the engine have to execute.
+// Run reads the file from the path by itself, while RunFile works from a file already loaded by the engine, which
+// is shared across all rules, so the file is read only once independently of the number of rules
 type Rule interface {
 	Run(path string) ([]Finding, error)
+	RunFile(file *File) ([]Finding, error)
+}
+
+// MetadataProvider is implemented by rules that can describe themselves through the metadata of the advisory they
+// report. Any rule embedding Metadata, like text.Rule, implements it
+type MetadataProvider interface {
+	GetMetadata() Metadata
+}
+
+// getRuleID returns the ID of the rule when it provides its metadata, otherwise an empty string
+func getRuleID(rule Rule) string {
+	if provider, ok := rule.(MetadataProvider); ok {
+		return provider.GetMetadata().ID
+	}
+
+	return ""
 }
 
 // Metadata holds information for the rule to match a useful advisory
@@ -33,3 +51,8 @@ type Metadata struct {
 	SafeExample   string
 	UnsafeExample string
 }
+
+// GetMetadata returns the metadata itself, so rules embedding it implement MetadataProvider
+func (m Metadata) GetMetadata() Metadata {
+	return m
+}
@@ -0,0 +1,258 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ruletest checks rules against the examples of their metadata. Each rule must report at least one finding in
+// its unsafe example and none in its safe example, so broken and over-matching rules are found by the rule authors
+// tests, like:
+//
+//	func TestRules(t *testing.T) {
+//		ruletest.Run(t, rules...)
+//	}
+package ruletest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	engine "github.com/ZupIT/horusec-engine"
+	"github.com/ZupIT/horusec-engine/text"
+)
+
+// ExpectMarker marks the lines of the unsafe example where a finding is expected, usually in a comment like
+// "// horusec-expect". When the unsafe example has markers, the rule must report findings in all marked lines and
+// only on them, otherwise any finding is accepted
+const ExpectMarker = "horusec-expect"
+
+// Examples checked by the harness
+const (
+	SafeExample   = "SafeExample"
+	UnsafeExample = "UnsafeExample"
+)
+
+// exampleFilename is the name of the file used to run the rules with the examples, the extension is changed to the
+// first one of the rule extensions when the rule has any
+const exampleFilename = "example"
+
+// Problem is a problem found when checking a rule with its examples
+type Problem struct {
+	RuleID  string
+	Example string // Example is SafeExample or UnsafeExample, empty when the problem isn't related to an example
+	Message string
+}
+
+// String returns the problem prefixed by the rule ID and the example
+func (p Problem) String() string {
+	if p.Example == "" {
+		return fmt.Sprintf("%s: %s", p.RuleID, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", p.RuleID, p.Example, p.Message)
+}
+
+// Run checks each rule in a subtest named by the rule ID, reporting its problems as test errors
+func Run(t *testing.T, rules ...engine.Rule) {
+	t.Helper()
+
+	for _, rule := range rules {
+		rule := rule
+
+		t.Run(ruleMetadata(rule).ID, func(t *testing.T) {
+			for _, problem := range Check(rule) {
+				t.Error(problem.String())
+			}
+		})
+	}
+}
+
+// CheckAll checks all rules, returning the problems of all of them
+func CheckAll(rules ...engine.Rule) []Problem {
+	var problems []Problem
+
+	for _, rule := range rules {
+		problems = append(problems, Check(rule)...)
+	}
+
+	return problems
+}
+
+// Check runs the rule against the examples of its metadata and returns the problems found. The rule must provide
+// its metadata with both examples, report at least one finding in the unsafe example, in the lines marked with
+// ExpectMarker when there are any, and no findings in the safe example. Errors and panics of the rule are also
+// reported as problems
+func Check(rule engine.Rule) []Problem {
+	if _, ok := rule.(engine.MetadataProvider); !ok {
+		return []Problem{{Message: "rule doesn't provide its metadata, so it doesn't have examples"}}
+	}
+
+	metadata := ruleMetadata(rule)
+	checker := &checker{rule: rule, metadata: metadata, filename: exampleFilename + ruleExtension(rule)}
+
+	checker.checkUnsafeExample()
+	checker.checkSafeExample()
+
+	return checker.problems
+}
+
+// checker accumulates the problems found when checking a rule
+type checker struct {
+	rule     engine.Rule
+	metadata engine.Metadata
+	filename string
+	problems []Problem
+}
+
+func (c *checker) addProblem(example, format string, args ...interface{}) {
+	c.problems = append(c.problems, Problem{
+		RuleID:  c.metadata.ID,
+		Example: example,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkUnsafeExample checks that the rule reports findings in the unsafe example, in the expected lines if any
+func (c *checker) checkUnsafeExample() {
+	findings, ok := c.run(UnsafeExample, c.metadata.UnsafeExample)
+	if !ok {
+		return
+	}
+
+	if len(findings) == 0 {
+		c.addProblem(UnsafeExample, "expected at least one finding, but got none")
+
+		return
+	}
+
+	if expected := expectedLines(c.metadata.UnsafeExample); len(expected) > 0 {
+		c.checkExpectedLines(findings, expected)
+	}
+}
+
+// checkExpectedLines checks that the rule reports findings in all expected lines of the unsafe example, and only on
+// them
+func (c *checker) checkExpectedLines(findings []engine.Finding, expected map[int]bool) {
+	found := map[int]bool{}
+
+	for index := range findings {
+		found[findings[index].SourceLocation.Line] = true
+		c.checkFindingLine(&findings[index], expected)
+	}
+
+	for _, line := range sortedLines(expected) {
+		if !found[line] {
+			c.addProblem(UnsafeExample, "expected a finding at line %d, but got none", line)
+		}
+	}
+}
+
+// checkFindingLine checks that the finding is on one of the expected lines, file level findings are accepted since
+// they don't have a line
+func (c *checker) checkFindingLine(finding *engine.Finding, expected map[int]bool) {
+	if line := finding.SourceLocation.Line; line > 0 && !expected[line] {
+		c.addProblem(UnsafeExample, "unexpected finding at line %d: %s", line, finding.CodeSample)
+	}
+}
+
+// checkSafeExample checks that the rule doesn't report findings in the safe example
+func (c *checker) checkSafeExample() {
+	findings, ok := c.run(SafeExample, c.metadata.SafeExample)
+	if !ok {
+		return
+	}
+
+	for _, finding := range findings {
+		c.addProblem(SafeExample, "unexpected finding at line %d: %s", finding.SourceLocation.Line, finding.CodeSample)
+	}
+}
+
+// run runs the rule with the example, adding a problem when the example is missing or the rule fails
+func (c *checker) run(example, content string) ([]engine.Finding, bool) {
+	if strings.TrimSpace(content) == "" {
+		c.addProblem(example, "missing example")
+
+		return nil, false
+	}
+
+	return c.runFile(example, content)
+}
+
+// runFile runs the rule with the content of the example, adding a problem when the rule fails or panics
+func (c *checker) runFile(example, content string) (findings []engine.Finding, ok bool) {
+	defer func() {
+		if value := recover(); value != nil {
+			c.addProblem(example, "rule panicked: %v", value)
+
+			findings, ok = nil, false
+		}
+	}()
+
+	findings, err := c.rule.RunFile(engine.NewFile(c.filename, []byte(content)))
+	if err != nil {
+		c.addProblem(example, "rule failed: %v", err)
+	}
+
+	return findings, err == nil
+}
+
+// expectedLines returns the 1-based lines of the example marked with ExpectMarker
+func expectedLines(example string) map[int]bool {
+	lines := map[int]bool{}
+
+	for index, line := range strings.Split(example, "\n") {
+		if strings.Contains(line, ExpectMarker) {
+			lines[index+1] = true
+		}
+	}
+
+	return lines
+}
+
+// sortedLines returns the lines in ascending order
+func sortedLines(lines map[int]bool) []int {
+	var sorted []int
+
+	for line := 1; len(sorted) < len(lines); line++ {
+		if lines[line] {
+			sorted = append(sorted, line)
+		}
+	}
+
+	return sorted
+}
+
+// ruleMetadata returns the metadata of the rule, or an empty metadata if the rule doesn't provide it
+func ruleMetadata(rule engine.Rule) engine.Metadata {
+	if provider, ok := rule.(engine.MetadataProvider); ok {
+		return provider.GetMetadata()
+	}
+
+	return engine.Metadata{}
+}
+
+// ruleExtension returns the first extension of a text rule, so the examples are analyzed by rules restricted to some
+// extensions. An empty string is returned for other rules and text rules that accept any extension
+func ruleExtension(rule engine.Rule) string {
+	textRule, ok := rule.(*text.Rule)
+	if !ok {
+		return ""
+	}
+
+	for _, extension := range textRule.Extensions {
+		if extension != engine.AcceptAnyExtension {
+			return extension
+		}
+	}
+
+	return ""
+}
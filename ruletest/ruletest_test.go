@@ -0,0 +1,190 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruletest
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	engine "github.com/ZupIT/horusec-engine"
+	"github.com/ZupIT/horusec-engine/text"
+)
+
+type ruleWithoutMetadataMock struct{}
+
+func (r *ruleWithoutMetadataMock) Run(_ string) ([]engine.Finding, error) {
+	return nil, nil
+}
+
+func (r *ruleWithoutMetadataMock) RunFile(_ *engine.File) ([]engine.Finding, error) {
+	return nil, nil
+}
+
+type failingRuleMock struct {
+	engine.Metadata
+	err error
+}
+
+func (r *failingRuleMock) Run(_ string) ([]engine.Finding, error) {
+	return nil, r.err
+}
+
+func (r *failingRuleMock) RunFile(file *engine.File) ([]engine.Finding, error) {
+	if r.err == nil {
+		panic("invalid file " + file.Path)
+	}
+
+	return nil, r.err
+}
+
+func newWeakHashRule(safeExample, unsafeExample string) *text.Rule {
+	return &text.Rule{
+		Metadata: engine.Metadata{
+			ID:            "HS-JAVA-1",
+			Name:          "Weak hash",
+			Severity:      "MEDIUM",
+			Confidence:    "HIGH",
+			SafeExample:   safeExample,
+			UnsafeExample: unsafeExample,
+		},
+		Type:        text.OrMatch,
+		Expressions: []*regexp.Regexp{regexp.MustCompile(`getInstance\("(MD5|SHA1)"\)`)},
+		Extensions:  []string{".java"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Should pass for rules that match their examples", func(t *testing.T) {
+		Run(t,
+			newWeakHashRule(`getInstance("SHA-256");`, `getInstance("MD5");`),
+			newWeakHashRule(`getInstance("SHA-256");`, "a();\ngetInstance(\"MD5\"); // horusec-expect\nb();"),
+		)
+	})
+}
+
+// nolint:funlen // table of rules with problems
+func TestCheck(t *testing.T) {
+	testcases := []struct {
+		name     string
+		rule     engine.Rule
+		expected []Problem
+	}{
+		{
+			name: "Should return no problems when the rule matches the unsafe example only",
+			rule: newWeakHashRule(`getInstance("SHA-256");`, `getInstance("MD5");`),
+		},
+		{
+			name: "Should return no problems when the rule matches the marked lines",
+			rule: newWeakHashRule(`getInstance("SHA-256");`,
+				"getInstance(\"MD5\"); // horusec-expect\nok();\ngetInstance(\"SHA1\"); // horusec-expect"),
+		},
+		{
+			name: "Should return problems for rules without examples",
+			rule: newWeakHashRule("", " \n"),
+			expected: []Problem{
+				{RuleID: "HS-JAVA-1", Example: UnsafeExample, Message: "missing example"},
+				{RuleID: "HS-JAVA-1", Example: SafeExample, Message: "missing example"},
+			},
+		},
+		{
+			name: "Should return a problem when the rule doesn't match the unsafe example",
+			rule: newWeakHashRule(`getInstance("SHA-256");`, `getInstance("MD4");`),
+			expected: []Problem{
+				{RuleID: "HS-JAVA-1", Example: UnsafeExample, Message: "expected at least one finding, but got none"},
+			},
+		},
+		{
+			name: "Should return a problem when the rule over-matches the safe example",
+			rule: newWeakHashRule("ok();\ngetInstance(\"SHA1\");", `getInstance("MD5");`),
+			expected: []Problem{
+				{RuleID: "HS-JAVA-1", Example: SafeExample, Message: `unexpected finding at line 2: getInstance("SHA1");`},
+			},
+		},
+		{
+			name: "Should return problems when the findings are not in the marked lines",
+			rule: newWeakHashRule(`getInstance("SHA-256");`,
+				"getInstance(\"MD5\");\n// horusec-expect\ngetInstance(\"SHA1\"); // horusec-expect"),
+			expected: []Problem{
+				{RuleID: "HS-JAVA-1", Example: UnsafeExample, Message: `unexpected finding at line 1: getInstance("MD5");`},
+				{RuleID: "HS-JAVA-1", Example: UnsafeExample, Message: "expected a finding at line 2, but got none"},
+			},
+		},
+		{
+			name: "Should return problems when the rule fails",
+			rule: &failingRuleMock{
+				Metadata: engine.Metadata{ID: "HS-FAIL-1", SafeExample: "a", UnsafeExample: "b"},
+				err:      errors.New("test"),
+			},
+			expected: []Problem{
+				{RuleID: "HS-FAIL-1", Example: UnsafeExample, Message: "rule failed: test"},
+				{RuleID: "HS-FAIL-1", Example: SafeExample, Message: "rule failed: test"},
+			},
+		},
+		{
+			name: "Should return problems when the rule panics",
+			rule: &failingRuleMock{Metadata: engine.Metadata{ID: "HS-PANIC-1", SafeExample: "a", UnsafeExample: "b"}},
+			expected: []Problem{
+				{RuleID: "HS-PANIC-1", Example: UnsafeExample, Message: "rule panicked: invalid file example"},
+				{RuleID: "HS-PANIC-1", Example: SafeExample, Message: "rule panicked: invalid file example"},
+			},
+		},
+		{
+			name:     "Should return a problem when the rule doesn't provide its metadata",
+			rule:     &ruleWithoutMetadataMock{},
+			expected: []Problem{{Message: "rule doesn't provide its metadata, so it doesn't have examples"}},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Check(tt.rule))
+		})
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	t.Run("Should return the problems of all rules", func(t *testing.T) {
+		problems := CheckAll(
+			newWeakHashRule(`getInstance("SHA-256");`, `getInstance("MD5");`),
+			newWeakHashRule(`getInstance("MD5");`, `getInstance("MD5");`),
+			&ruleWithoutMetadataMock{},
+		)
+
+		assert.Len(t, problems, 2)
+	})
+}
+
+func TestProblemString(t *testing.T) {
+	t.Run("Should prefix the message with the rule ID and the example", func(t *testing.T) {
+		problem := Problem{RuleID: "HS-JAVA-1", Example: SafeExample, Message: "unexpected finding"}
+
+		assert.Equal(t, "HS-JAVA-1: SafeExample: unexpected finding", problem.String())
+		assert.Equal(t, "HS-JAVA-1: missing metadata", Problem{RuleID: "HS-JAVA-1", Message: "missing metadata"}.String())
+	})
+}
+
+func TestCheckUsesRuleExtension(t *testing.T) {
+	t.Run("Should analyze the examples with the extension of the rule", func(t *testing.T) {
+		rule := newWeakHashRule(`getInstance("SHA-256");`, `getInstance("MD5");`)
+		rule.Extensions = []string{engine.AcceptAnyExtension, ".kt"}
+
+		assert.Empty(t, Check(rule))
+		assert.Equal(t, ".kt", ruleExtension(rule))
+		assert.Empty(t, ruleExtension(&ruleWithoutMetadataMock{}))
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import "context"
+
+// Stream works like Run, but instead of waiting the whole analysis to finish, each finding is sent through the
+// findings channel as soon as a rule produces it.
+// The findings channel is closed when the analysis finishes. After that, the errors channel receives the error that
+// stopped the analysis or, with the ContinueOnError policy, the ScanErrors of the files and rules that failed, if
+// any, and is closed too, so receiving from it after the findings channel is closed always
+// returns the analysis result. To stop the analysis early, cancel the context, it's also required to stop the
+// analysis if the findings channel will no longer be consumed. The findings are classified with the baseline informed
+// through WithBaseline, but the fixed ones are only available through Scan
+func (e *Engine) Stream(ctx context.Context, projectPath string, rules ...Rule) (<-chan Finding, <-chan error) {
+	findings := make(chan Finding)
+	errs := make(chan error, 1)
+
+	go func() {
+		scanErrors, err := e.runProject(ctx, projectPath, rules, func(ctx context.Context, newFindings []Finding) error {
+			return sendFindings(ctx, findings, newFindings)
+		})
+
+		close(findings)
+		sendError(errs, err, scanErrors)
+	}()
+
+	return findings, errs
+}
+
+// sendError sends the error that stopped the analysis or, if there is none, the errors of the files and rules that
+// failed through the channel, if any, and closes it
+func sendError(errs chan<- error, err error, scanErrors []*ScanError) {
+	defer close(errs)
+
+	if err == nil && len(scanErrors) > 0 {
+		err = ScanErrors(scanErrors)
+	}
+
+	if err != nil {
+		errs <- err
+	}
+}
+
+// runProject runs the analysis of the project path, handling the findings with handleFindings
+func (e *Engine) runProject(ctx context.Context, projectPath string, rules []Rule,
+	handleFindings findingsHandler) ([]*ScanError, error) {
+	target, err := e.newProjectTarget(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.run(ctx, target, rules, newBaselineMatcher(e.baseline), handleFindings)
+}
+
+// sendFindings sends each one of the findings through the channel, giving up when the context is done
+func sendFindings(ctx context.Context, findings chan<- Finding, newFindings []Finding) error {
+	for index := range newFindings {
+		select {
+		case findings <- newFindings[index]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
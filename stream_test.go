@@ -0,0 +1,69 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineStream(t *testing.T) {
+	t.Run("Should stream all findings and close the channels without error", func(t *testing.T) {
+		findings, errs := NewEngine(2, ".go").Stream(context.Background(), newTestProject(t, 10),
+			newRuleMock([]Finding{{}, {}}, nil))
+
+		total := 0
+		for range findings {
+			total++
+		}
+
+		assert.Equal(t, 20, total)
+		assert.NoError(t, <-errs)
+	})
+
+	t.Run("Should send the error that stopped the analysis", func(t *testing.T) {
+		findings, errs := NewEngine(2, ".go").Stream(context.Background(), newTestProject(t, 10),
+			newRuleMock(nil, errors.New("test error")))
+
+		for range findings {
+			t.Fatal("should not receive any finding")
+		}
+
+		var scanErr *ScanError
+		assert.ErrorAs(t, <-errs, &scanErr)
+		assert.EqualError(t, scanErr.Err, "test error")
+	})
+
+	t.Run("Should stop the analysis when consumer cancels the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		findings, errs := NewEngine(2, ".go").Stream(ctx, newTestProject(t, 100), newRuleMock([]Finding{{}}, nil))
+
+		<-findings
+		cancel()
+
+		total := 1
+		for range findings {
+			total++
+		}
+
+		assert.Less(t, total, 100)
+		assert.ErrorIs(t, <-errs, context.Canceled)
+	})
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+// Kinds of the inline annotations that suppress findings
+const (
+	SuppressionHorusecIgnore = "horusec-ignore"
+	SuppressionNoSec         = "nosec"
+)
+
+// Suppression represents an inline annotation in the source code, like "// horusec-ignore" or "# nosec HS-JAVA-1",
+// that marks a finding as reviewed. It's recorded in the suppressed finding, so the suppressions can be audited
+type Suppression struct {
+	Kind     string   // Kind is the annotation used, SuppressionHorusecIgnore or SuppressionNoSec
+	RuleIDs  []string // RuleIDs holds the IDs of the rules suppressed by the annotation, all rules when it's empty
+	Location Location // Location is the location of the annotation in the file
+}
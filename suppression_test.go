@@ -0,0 +1,55 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSuppressedFindings() []Finding {
+	return []Finding{
+		{ID: "HS-TEST-1"},
+		{ID: "HS-TEST-2", Suppression: &Suppression{Kind: SuppressionNoSec, RuleIDs: []string{"HS-TEST-2"}}},
+		{ID: "HS-TEST-3", Suppression: &Suppression{Kind: SuppressionHorusecIgnore}},
+	}
+}
+
+func TestEngineRunWithSuppressedFindings(t *testing.T) {
+	t.Run("Should keep the suppressed findings by default", func(t *testing.T) {
+		findings, err := NewEngine(2, ".go").Run(context.Background(), newTestProject(t, 2),
+			newRuleMock(newSuppressedFindings(), nil))
+
+		assert.NoError(t, err)
+		assert.Len(t, findings, 6)
+	})
+
+	t.Run("Should drop the suppressed findings with WithoutSuppressedFindings", func(t *testing.T) {
+		engine := NewEngineWithOptions(2, []string{".go"}, WithoutSuppressedFindings())
+		rule := newRuleMock(newSuppressedFindings(), nil)
+
+		findings, err := engine.Run(context.Background(), newTestProject(t, 2), rule)
+
+		assert.NoError(t, err)
+		if assert.Len(t, findings, 2) {
+			assert.Equal(t, "HS-TEST-1", findings[0].ID)
+			assert.Equal(t, "HS-TEST-1", findings[1].ID)
+		}
+
+		assert.Equal(t, newSuppressedFindings(), rule.findings, "the findings of the rule should not be changed")
+	})
+}
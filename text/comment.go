@@ -0,0 +1,144 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commentSyntax holds the tokens that begin the comments of a language and the expression that finds the suppression
+// annotations right after them, which can also be written like "#nosec". The rule IDs are captured with the rest of
+// the line, since they are separated by spaces, commas or colons, like "# nosec HS-JAVA-1, HS-JAVA-2"
+type commentSyntax struct {
+	prefixes   []string // prefixes holds the prefixes of the lines that only have a comment
+	annotation *regexp.Regexp
+}
+
+// newCommentSyntax creates the syntax of the comments beginning with the tokens. Languages with "/*" block comments
+// also accept the "*" at the beginning of the continuation lines of these comments
+func newCommentSyntax(tokens ...string) *commentSyntax {
+	prefixes, alternatives := tokens, quoteMeta(tokens)
+
+	if contains(tokens, "/*") {
+		prefixes = append(prefixes, "*")
+		alternatives = append(alternatives, `^[ \t]*\*`)
+	}
+
+	return &commentSyntax{
+		prefixes: prefixes,
+		annotation: regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)` +
+			`[ \t]*#?(horusec-ignore|nosec)((?:[ \t,:]+[\w.-]+)*)`),
+	}
+}
+
+// quoteMeta quotes the metacharacters of each one of the texts
+func quoteMeta(texts []string) []string {
+	quoted := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		quoted = append(quoted, regexp.QuoteMeta(text))
+	}
+
+	return quoted
+}
+
+// contains checks if the value is one of the values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the comment syntaxes shared by many languages
+var (
+	cLikeComments  = newCommentSyntax("//", "/*")
+	hashComments   = newCommentSyntax("#")
+	hashCComments  = newCommentSyntax("#", "//", "/*")
+	sqlComments    = newCommentSyntax("--", "/*")
+	dashComments   = newCommentSyntax("--")
+	markupComments = newCommentSyntax("<!--")
+	iniComments    = newCommentSyntax(";", "#")
+	lispComments   = newCommentSyntax(";")
+
+	// unknownComments is used by the files whose language is unknown, it accepts the comments of all the languages
+	unknownComments = newCommentSyntax("//", "/*", "#", "--", "<!--", ";")
+)
+
+// commentSyntaxes maps the extensions of the files of the supported languages into the syntax of their comments
+var commentSyntaxes = map[string]*commentSyntax{
+	".c": cLikeComments, ".h": cLikeComments, ".cc": cLikeComments, ".cpp": cLikeComments, ".hpp": cLikeComments,
+	".cs": cLikeComments, ".java": cLikeComments, ".kt": cLikeComments, ".kts": cLikeComments,
+	".scala": cLikeComments, ".groovy": cLikeComments, ".gradle": cLikeComments, ".go": cLikeComments,
+	".js": cLikeComments, ".jsx": cLikeComments, ".mjs": cLikeComments, ".ts": cLikeComments, ".tsx": cLikeComments,
+	".swift": cLikeComments, ".dart": cLikeComments, ".rs": cLikeComments, ".m": cLikeComments,
+	".json": cLikeComments,
+
+	".py": hashComments, ".rb": hashComments, ".sh": hashComments, ".bash": hashComments, ".zsh": hashComments,
+	".yaml": hashComments, ".yml": hashComments, ".toml": hashComments, ".r": hashComments, ".pl": hashComments,
+	".ps1": hashComments, ".properties": hashComments, ".conf": hashComments, ".cfg": hashComments,
+	".ex": hashComments, ".exs": hashComments, ".dockerfile": hashComments,
+
+	".php": hashCComments, ".tf": hashCComments, ".hcl": hashCComments,
+
+	".sql": sqlComments, ".lua": dashComments, ".hs": dashComments,
+
+	".html": markupComments, ".htm": markupComments, ".xhtml": markupComments, ".xml": markupComments,
+	".svg": markupComments, ".config": markupComments, ".csproj": markupComments, ".jsp": markupComments,
+
+	".ini": iniComments, ".clj": lispComments, ".lisp": lispComments, ".el": lispComments,
+}
+
+// getCommentSyntax returns the syntax of the comments of the file according to its extension
+func getCommentSyntax(name string) *commentSyntax {
+	if syntax, ok := commentSyntaxes[strings.ToLower(filepath.Ext(name))]; ok {
+		return syntax
+	}
+
+	return unknownComments
+}
+
+// isInsideString checks if the end of the text is inside a string literal, delimited by double quotes, single quotes
+// or backticks, where a backslash escapes the next character
+func isInsideString(text []byte) bool {
+	var delimiter byte
+
+	for index := 0; index < len(text); index++ {
+		switch {
+		case delimiter == 0:
+			delimiter = stringDelimiter(text[index])
+		case text[index] == '\\':
+			index++
+		case text[index] == delimiter:
+			delimiter = 0
+		}
+	}
+
+	return delimiter != 0
+}
+
+// stringDelimiter returns the character when it begins a string literal, or 0 otherwise
+func stringDelimiter(c byte) byte {
+	if c == '"' || c == '\'' || c == '`' {
+		return c
+	}
+
+	return 0
+}
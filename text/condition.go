@@ -0,0 +1,560 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Condition is a node of the expression tree of a text rule, built with All, Any, Not and Regex nodes, like
+// "A and not B", which is &All{Conditions: []Condition{&Regex{a}, &Not{&Regex{b}}}}. A condition is satisfied when it
+// has at least one match in the file, and each match of the root condition is reported as a finding:
+//
+//   - Regex matches each occurrence of its regular expression
+//   - Any is satisfied when any of its conditions is, its matches are the matches of all satisfied conditions
+//   - All is satisfied when all its conditions are, its matches are the matches of its first condition that is not a
+//     Not, since they are the code that the rule looks for, while the other conditions only restrict when it's
+//     reported. When all conditions are Not, the matches of the first one are used. With a Scope, only the matches
+//     that have the other conditions satisfied around them, like on the same line, are reported
+//   - Not is satisfied when its condition has no match in the file, and matches the first match of its anchor, or the
+//     whole file when it has no anchor or the anchor has no match
+//
+// A match of the whole file is reported as a file level finding, without line and code sample
+type Condition interface {
+	compile(c *conditionCompiler) (node, error)
+}
+
+// Regex is a condition that matches each occurrence of the regular expression. Group is the name or the number of
+// the capture group reported as the match, like the secret of `password\s*=\s*"(?P<secret>.+)"`, instead of the whole
+// match. Matches where the group doesn't participate are reported whole
+type Regex struct {
+	Expression *regexp.Regexp
+	Group      string
+}
+
+// Any is a condition satisfied when any of the conditions is satisfied
+type Any struct {
+	Conditions []Condition
+}
+
+// All is a condition satisfied when all the conditions are satisfied. When FirstMatchOnly is set, only the first
+// match of the first condition that is not a Not is reported, like AndMatch rules do. Scope restricts where the
+// other conditions must be satisfied around each match, and Window is the number of lines used by WindowScope
+type All struct {
+	Conditions     []Condition
+	FirstMatchOnly bool
+	Scope          ScopeType
+	Window         int
+}
+
+// Not is a condition satisfied when its condition has no match in the file. Since there is no code of the condition
+// to report, the first match of the Anchor, like the "<configuration>" tag of a file missing some setting, is
+// reported as its location and code sample. The whole file is reported when Anchor is nil or has no match
+type Not struct {
+	Condition Condition
+	Anchor    Condition
+}
+
+// errNilCondition is returned when the expression tree has a nil condition or expression
+var errNilCondition = errors.New("nil condition")
+
+// conditionOf maps the match type and the expressions of a rule into a condition:
+//
+//   - OrMatch and Regular are Any of the expressions, reporting each match of each expression
+//   - AndMatch is All of the expressions, reporting only the first match of the first expression. With a scope other
+//     than FileScope, each match of the first expression with the other expressions around it is reported
+//   - NotMatch is Any of Not of each expression, reporting the first match of the anchor, or the whole file, once
+//     for each expression without match
+//
+// The group is set on the expressions that have it. The scope and window are only allowed for AndMatch, and the
+// anchor for NotMatch
+func conditionOf(matchType MatchType, expressions []*regexp.Regexp, group string, anchor *regexp.Regexp,
+	scope ScopeType, window int,
+) (Condition, error) {
+	if err := checkMatchTypeOptions(matchType, anchor, scope); err != nil {
+		return nil, err
+	}
+
+	conditions := expressionsConditions(matchType, expressions, group, anchor)
+
+	switch matchType {
+	case OrMatch, Regular, NotMatch:
+		return &Any{Conditions: conditions}, nil
+	case AndMatch:
+		return &All{Conditions: conditions, FirstMatchOnly: scope == FileScope, Scope: scope, Window: window}, nil
+	}
+
+	return nil, fmt.Errorf("invalid rule type")
+}
+
+// checkMatchTypeOptions checks that the scope and the anchor are only used by the match types that support them
+func checkMatchTypeOptions(matchType MatchType, anchor *regexp.Regexp, scope ScopeType) error {
+	if scope != FileScope && matchType != AndMatch {
+		return errors.New("scope is only supported by the AndMatch type")
+	}
+
+	if anchor != nil && matchType != NotMatch {
+		return errors.New("anchor is only supported by the NotMatch type")
+	}
+
+	return nil
+}
+
+// expressionsConditions maps each expression into a Regex condition, which is wrapped by a Not for NotMatch
+func expressionsConditions(matchType MatchType, expressions []*regexp.Regexp, group string,
+	anchor *regexp.Regexp) []Condition {
+	conditions := make([]Condition, 0, len(expressions))
+
+	for _, expression := range expressions {
+		var condition Condition = newRegex(expression, group)
+		if matchType == NotMatch {
+			condition = newNot(condition, anchor)
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+// newRegex creates the Regex condition of the expression, with the group only when the expression has it
+func newRegex(expression *regexp.Regexp, group string) *Regex {
+	if _, ok := groupIndex(expression, group); !ok {
+		return &Regex{Expression: expression}
+	}
+
+	return &Regex{Expression: expression, Group: group}
+}
+
+// newNot creates the Not of the condition with the anchor expression, if it's set
+func newNot(condition Condition, anchor *regexp.Regexp) *Not {
+	if anchor == nil {
+		return &Not{Condition: condition}
+	}
+
+	return &Not{Condition: condition, Anchor: &Regex{Expression: anchor}}
+}
+
+// groupIndex returns the index of the capture group of the expression with the name or number, the whole match has
+// the index 0, which is also returned for an empty group
+func groupIndex(expression *regexp.Regexp, group string) (int, bool) {
+	if group == "" {
+		return 0, true
+	}
+
+	if number, err := strconv.Atoi(group); err == nil {
+		return number, number >= 0 && number <= expression.NumSubexp()
+	}
+
+	return namedGroupIndex(expression, group)
+}
+
+// namedGroupIndex returns the index of the first capture group of the expression with the name
+func namedGroupIndex(expression *regexp.Regexp, name string) (int, bool) {
+	for index, groupName := range expression.SubexpNames() {
+		if groupName == name {
+			return index, true
+		}
+	}
+
+	return 0, false
+}
+
+// match is a match of a condition in the file, it's the span of a match of a regular expression, or the whole file.
+// The submatches are the indexes of the capture groups of the match, as returned by regexp.FindSubmatchIndex, and
+// expression is the index of the expression that matched, they are only set for the matches of the expressions
+type match struct {
+	start      int
+	end        int
+	wholeFile  bool
+	expression int
+	submatches []int
+}
+
+// evaluation holds the data used to evaluate the conditions of a rule in a file
+type evaluation struct {
+	file        *File
+	expressions []*regexp.Regexp
+	candidates  []bool    // candidates holds which expressions can match the file according to the prefilter
+	matches     [][]match // matches holds the matches of each expression in the whole file, once it's evaluated
+	evaluated   []bool    // evaluated holds which expressions were already evaluated
+	region      span      // region is the part of the file where the conditions are evaluated, limited by scopes
+}
+
+// findAll returns all matches of the expression in the whole file, with the indexes of the capture groups when it
+// has any. The expression is evaluated only once, even if it's used by many scopes, and only if the prefilter found
+// the literals required by it in the file, otherwise it's known that there is no match
+func (e *evaluation) findAll(index int) []match {
+	if e.candidates[index] && !e.evaluated[index] {
+		e.evaluated[index] = true
+		e.matches[index] = findExpressionMatches(e.expressions[index], index, e.file.Content)
+	}
+
+	return e.matches[index]
+}
+
+// isWholeFile checks if the region being evaluated is the whole file
+func (e *evaluation) isWholeFile() bool {
+	return e.region.start == 0 && e.region.end == len(e.file.Content)
+}
+
+// findExpressionMatches returns all matches of the expression with the index in the content, with the indexes of the
+// capture groups when it has any
+func findExpressionMatches(expression *regexp.Regexp, index int, content []byte) []match {
+	findAll := expression.FindAllIndex
+	if expression.NumSubexp() > 0 {
+		findAll = expression.FindAllSubmatchIndex
+	}
+
+	var matches []match
+
+	for _, indexes := range findAll(content, -1) {
+		matches = append(matches, match{start: indexes[0], end: indexes[1], expression: index, submatches: indexes})
+	}
+
+	return matches
+}
+
+// node is a compiled condition, which can be evaluated in a file, it returns nil when the condition isn't satisfied
+type node interface {
+	evaluate(e *evaluation) []match
+}
+
+// program is the compiled expression tree of a rule, with all regular expressions of the tree and their prefilter
+type program struct {
+	root        node
+	expressions []*regexp.Regexp
+	prefilter   *prefilter
+}
+
+// conditionCompiler collects the regular expressions of the tree while compiling its conditions
+type conditionCompiler struct {
+	expressions []*regexp.Regexp
+}
+
+// compileCondition compiles the expression tree into a program
+func compileCondition(condition Condition) (*program, error) {
+	compiler := new(conditionCompiler)
+
+	root, err := compiler.compile(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &program{
+		root:        root,
+		expressions: compiler.expressions,
+		prefilter:   newPrefilter(compiler.expressions),
+	}, nil
+}
+
+// namedGroups returns the value of each named capture group of the expression that matched, the groups that didn't
+// participate in the match are empty. Matches of the whole file have no groups
+func (p *program) namedGroups(file *File, m match) map[string]string {
+	groups := map[string]string{}
+	if m.submatches == nil {
+		return groups
+	}
+
+	for index, name := range p.expressions[m.expression].SubexpNames() {
+		if start := m.submatches[2*index]; name != "" && start >= 0 {
+			groups[name] = string(file.Content[start:m.submatches[2*index+1]])
+		}
+	}
+
+	return groups
+}
+
+// findMatches returns the matches of the expression tree in the file, only the candidates expressions are evaluated
+func (p *program) findMatches(file *File, candidates []bool) []match {
+	return p.root.evaluate(&evaluation{
+		file:        file,
+		expressions: p.expressions,
+		candidates:  candidates,
+		matches:     make([][]match, len(p.expressions)),
+		evaluated:   make([]bool, len(p.expressions)),
+		region:      span{start: 0, end: len(file.Content)},
+	})
+}
+
+// compile compiles the condition, returning an error when it's nil
+func (c *conditionCompiler) compile(condition Condition) (node, error) {
+	if condition == nil {
+		return nil, errNilCondition
+	}
+
+	return condition.compile(c)
+}
+
+// compileOptional compiles the condition like compile, but returns a nil node when the condition is nil
+func (c *conditionCompiler) compileOptional(condition Condition) (node, error) {
+	if condition == nil {
+		return nil, nil
+	}
+
+	return condition.compile(c)
+}
+
+// compileConditions compiles each one of the conditions
+func (c *conditionCompiler) compileConditions(conditions []Condition) ([]node, error) {
+	nodes := make([]node, 0, len(conditions))
+
+	for _, condition := range conditions {
+		compiled, err := c.compile(condition)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, compiled)
+	}
+
+	return nodes, nil
+}
+
+// regexNode evaluates the regular expression with the index in the expressions of the program, group is the index
+// of the capture group reported as the match, or 0 for the whole match
+type regexNode struct {
+	index int
+	group int
+}
+
+func (r *Regex) compile(c *conditionCompiler) (node, error) {
+	if r.Expression == nil {
+		return nil, errNilCondition
+	}
+
+	group, ok := groupIndex(r.Expression, r.Group)
+	if !ok {
+		return nil, fmt.Errorf("unknown group %q of the expression %s", r.Group, r.Expression)
+	}
+
+	c.expressions = append(c.expressions, r.Expression)
+
+	return &regexNode{index: len(c.expressions) - 1, group: group}, nil
+}
+
+// evaluate returns the matches of the expression inside the region being evaluated, with the span of the group
+func (n *regexNode) evaluate(e *evaluation) []match {
+	all := e.findAll(n.index)
+	if n.group == 0 && e.isWholeFile() {
+		return all
+	}
+
+	var matches []match
+
+	for _, m := range all {
+		if m = n.groupMatch(m); e.region.contains(m) {
+			matches = append(matches, m)
+		}
+	}
+
+	return matches
+}
+
+// groupMatch returns the match with the span of the group, or the whole match when the group doesn't participate in
+// the match
+func (n *regexNode) groupMatch(m match) match {
+	if start := m.submatches[2*n.group]; start >= 0 {
+		m.start, m.end = start, m.submatches[2*n.group+1]
+	}
+
+	return m
+}
+
+// anyNode is the compiled Any condition
+type anyNode struct {
+	nodes []node
+}
+
+func (a *Any) compile(c *conditionCompiler) (node, error) {
+	nodes, err := c.compileConditions(a.Conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anyNode{nodes: nodes}, nil
+}
+
+func (n *anyNode) evaluate(e *evaluation) []match {
+	var matches []match
+
+	for _, child := range n.nodes {
+		matches = append(matches, child.evaluate(e)...)
+	}
+
+	return matches
+}
+
+// allNode is the compiled All condition, primary is the index of the node whose matches are reported
+type allNode struct {
+	nodes          []node
+	primary        int
+	firstMatchOnly bool
+	scope          ScopeType
+	window         int
+}
+
+func (a *All) compile(c *conditionCompiler) (node, error) {
+	if err := validateScope(a.Scope, a.Window); err != nil {
+		return nil, err
+	}
+
+	nodes, err := c.compileConditions(a.Conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAllNode(a, nodes), nil
+}
+
+// validateScope checks if the scope is one of the scope types and the window is not negative
+func validateScope(scope ScopeType, window int) error {
+	if scope < FileScope || scope > IndentScope || window < 0 {
+		return fmt.Errorf("invalid scope %d with window %d", scope, window)
+	}
+
+	return nil
+}
+
+// newAllNode creates the compiled All condition with the compiled nodes of its conditions
+func newAllNode(a *All, nodes []node) *allNode {
+	return &allNode{
+		nodes:          nodes,
+		primary:        primaryIndex(a.Conditions),
+		firstMatchOnly: a.FirstMatchOnly,
+		scope:          a.Scope,
+		window:         a.Window,
+	}
+}
+
+// primaryIndex returns the index of the first condition that is not a Not, or 0 when all conditions are Not
+func primaryIndex(conditions []Condition) int {
+	for index, condition := range conditions {
+		if _, isNot := condition.(*Not); !isNot {
+			return index
+		}
+	}
+
+	return 0
+}
+
+// evaluate returns the matches of the primary node when all nodes are satisfied, only the first one with first match
+// only
+func (n *allNode) evaluate(e *evaluation) []match {
+	if n.scope != FileScope {
+		return n.evaluateScoped(e)
+	}
+
+	matches := n.evaluateAll(e)
+	if n.firstMatchOnly && len(matches) > 1 {
+		return matches[:1]
+	}
+
+	return matches
+}
+
+// evaluateAll returns the matches of the primary node when all nodes are satisfied, stopping at the first node that
+// isn't satisfied
+func (n *allNode) evaluateAll(e *evaluation) []match {
+	var matches []match
+
+	for index, child := range n.nodes {
+		childMatches := child.evaluate(e)
+		if childMatches == nil {
+			return nil
+		}
+
+		if index == n.primary {
+			matches = childMatches
+		}
+	}
+
+	return matches
+}
+
+// evaluateScoped returns the matches of the primary node that have all other nodes satisfied in the scope around them
+func (n *allNode) evaluateScoped(e *evaluation) []match {
+	var matches []match
+
+	for _, m := range n.nodes[n.primary].evaluate(e) {
+		if !n.isSatisfiedAround(e, m) {
+			continue
+		}
+
+		matches = append(matches, m)
+
+		if n.firstMatchOnly {
+			break
+		}
+	}
+
+	return matches
+}
+
+// isSatisfiedAround checks if all nodes other than the primary are satisfied in the scope around the match
+func (n *allNode) isSatisfiedAround(e *evaluation, m match) bool {
+	region := e.region
+	e.region = region.intersect(scopeSpan(e.file, n.scope, n.window, m))
+
+	defer func() {
+		e.region = region
+	}()
+
+	for index, child := range n.nodes {
+		if index != n.primary && child.evaluate(e) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// notNode is the compiled Not condition, anchor is nil when the Not has no anchor
+type notNode struct {
+	node   node
+	anchor node
+}
+
+func (n *Not) compile(c *conditionCompiler) (node, error) {
+	compiled, err := c.compile(n.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor, err := c.compileOptional(n.Anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notNode{node: compiled, anchor: anchor}, nil
+}
+
+// evaluate returns the first match of the anchor when the node isn't satisfied, or a match of the whole file
+func (n *notNode) evaluate(e *evaluation) []match {
+	if n.node.evaluate(e) != nil {
+		return nil
+	}
+
+	if n.anchor != nil {
+		if anchorMatches := n.anchor.evaluate(e); anchorMatches != nil && !anchorMatches[0].wholeFile {
+			return anchorMatches[:1]
+		}
+	}
+
+	return []match{{wholeFile: true}}
+}
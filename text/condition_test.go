@@ -0,0 +1,341 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+// regex creates a Regex condition with the expression
+func regex(expression string) Condition {
+	return &Regex{Expression: regexp.MustCompile(expression)}
+}
+
+// findingsLines returns the line of each finding, file level findings have the line 0
+func findingsLines(findings []engine.Finding) []int {
+	lines := make([]int, 0, len(findings))
+
+	for index := range findings {
+		lines = append(lines, findings[index].SourceLocation.Line)
+	}
+
+	return lines
+}
+
+// nolint:funlen // table of conditions
+func TestRunFileWithCondition(t *testing.T) {
+	content := "import java.util.Random;\n" +
+		"Random a = new Random();\n" +
+		"double b = Math.random();\n" +
+		"Random c = new Random();\n"
+
+	testcases := []struct {
+		name      string
+		condition Condition
+		expected  []int
+	}{
+		{
+			name:      "Should report all matches of a regex",
+			condition: regex(`new Random\(\)`),
+			expected:  []int{2, 4},
+		},
+		{
+			name:      "Should report the matches of all satisfied conditions of any",
+			condition: &Any{Conditions: []Condition{regex(`Math\.random`), regex(`new Random\(\)`), regex(`Secure`)}},
+			expected:  []int{3, 2, 4},
+		},
+		{
+			name:      "Should report the matches of the first condition of all when all are satisfied",
+			condition: &All{Conditions: []Condition{regex(`new Random\(\)`), regex(`import java\.util`)}},
+			expected:  []int{2, 4},
+		},
+		{
+			name:      "Should report only the first match when all has first match only",
+			condition: &All{Conditions: []Condition{regex(`new Random\(\)`), regex(`import`)}, FirstMatchOnly: true},
+			expected:  []int{2},
+		},
+		{
+			name:      "Should report nothing when any condition of all is not satisfied",
+			condition: &All{Conditions: []Condition{regex(`new Random\(\)`), regex(`SecureRandom`)}},
+			expected:  []int{},
+		},
+		{
+			name: "Should report the matches of the first condition that is not a not",
+			condition: &All{Conditions: []Condition{
+				&Not{Condition: regex(`SecureRandom`)},
+				&Any{Conditions: []Condition{regex(`Math\.random`), regex(`new Random\(\)`)}},
+			}},
+			expected: []int{3, 2, 4},
+		},
+		{
+			name:      "Should report nothing when the condition of not is satisfied",
+			condition: &All{Conditions: []Condition{regex(`Math\.random`), &Not{Condition: regex(`import`)}}},
+			expected:  []int{},
+		},
+		{
+			name:      "Should report the whole file when the condition of not is not satisfied",
+			condition: &Not{Condition: regex(`SecureRandom`)},
+			expected:  []int{0},
+		},
+		{
+			name:      "Should report nothing for an empty any",
+			condition: &Any{},
+			expected:  []int{},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{Condition: tt.condition}
+
+			findings, err := rule.RunFile(engine.NewFile("Main.java", []byte(content)))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, findingsLines(findings))
+		})
+	}
+}
+
+func TestRunFileWithConditionWholeFile(t *testing.T) {
+	t.Run("Should report a finding of the whole file without code sample", func(t *testing.T) {
+		rule := &Rule{Condition: &Not{Condition: regex(`SecureRandom`)}}
+
+		findings, err := rule.RunFile(engine.NewFile("Main.java", []byte("new Random();")))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+
+		assert.Equal(t, engine.Location{Filename: "Main.java", FileLevel: true}, findings[0].SourceLocation)
+		assert.Empty(t, findings[0].CodeSample)
+	})
+}
+
+// nolint:funlen // table of anchors
+func TestRunFileWithAnchor(t *testing.T) {
+	content := "<project>\n  <configuration>\n    <debug>true</debug>\n  </configuration>\n</project>\n"
+
+	testcases := []struct {
+		name     string
+		rule     *Rule
+		expected engine.Location
+		sample   string
+	}{
+		{
+			name: "Should report the first match of the anchor of a not",
+			rule: &Rule{Condition: &Not{Condition: regex(`<secure>`), Anchor: regex(`<configuration>`)}},
+			expected: engine.Location{
+				Filename: "pom.xml", Line: 2, Column: 3, EndLine: 2, EndColumn: 18, Offset: 12, EndOffset: 27,
+			},
+			sample: "<configuration>",
+		},
+		{
+			name:     "Should report the whole file when the anchor has no match",
+			rule:     &Rule{Condition: &Not{Condition: regex(`<secure>`), Anchor: regex(`<settings>`)}},
+			expected: engine.Location{Filename: "pom.xml", FileLevel: true},
+		},
+		{
+			name: "Should report the first match of the anchor of NotMatch rules",
+			rule: &Rule{
+				Type:        NotMatch,
+				Expressions: []*regexp.Regexp{regexp.MustCompile(`<secure>`)},
+				Anchor:      regexp.MustCompile(`<debug>`),
+			},
+			expected: engine.Location{
+				Filename: "pom.xml", Line: 3, Column: 5, EndLine: 3, EndColumn: 12, Offset: 32, EndOffset: 39,
+			},
+			sample: "<debug>true</debug>",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := tt.rule.RunFile(engine.NewFile("pom.xml", []byte(content)))
+			require.NoError(t, err)
+			require.Len(t, findings, 1)
+
+			assert.Equal(t, tt.expected, findings[0].SourceLocation)
+			assert.Equal(t, tt.sample, findings[0].CodeSample)
+		})
+	}
+
+	t.Run("Should return an error for anchors of other match types", func(t *testing.T) {
+		rule := &Rule{
+			Type:        OrMatch,
+			Expressions: []*regexp.Regexp{regexp.MustCompile(`a`)},
+			Anchor:      regexp.MustCompile(`b`),
+		}
+
+		_, err := rule.RunFile(engine.NewFile("pom.xml", []byte("a")))
+		assert.Error(t, err)
+	})
+}
+
+// nolint:funlen // table of groups
+func TestRunFileWithGroup(t *testing.T) {
+	content := "password = \"s3cr3t\"\ntoken = \"abc\"\n"
+
+	testcases := []struct {
+		name     string
+		rule     *Rule
+		expected []engine.Location
+	}{
+		{
+			name: "Should report the span of the named group",
+			rule: &Rule{Condition: &Regex{
+				Expression: regexp.MustCompile(`password\s*=\s*"(?P<secret>[^"]+)"`),
+				Group:      "secret",
+			}},
+			expected: []engine.Location{{
+				Filename: "main.py", Line: 1, Column: 13, EndLine: 1, EndColumn: 19, Offset: 12, EndOffset: 18,
+			}},
+		},
+		{
+			name: "Should report the span of the numbered group",
+			rule: &Rule{Condition: &Regex{Expression: regexp.MustCompile(`token = "(\w+)"`), Group: "1"}},
+			expected: []engine.Location{{
+				Filename: "main.py", Line: 2, Column: 10, EndLine: 2, EndColumn: 13, Offset: 29, EndOffset: 32,
+			}},
+		},
+		{
+			name: "Should report the whole match when the group doesn't participate in the match",
+			rule: &Rule{Condition: &Regex{Expression: regexp.MustCompile(`token = "(\d+)?`), Group: "1"}},
+			expected: []engine.Location{{
+				Filename: "main.py", Line: 2, Column: 1, EndLine: 2, EndColumn: 10, Offset: 20, EndOffset: 29,
+			}},
+		},
+		{
+			name: "Should report the group of the rule only for the expressions that have it",
+			rule: &Rule{
+				Expressions: []*regexp.Regexp{
+					regexp.MustCompile(`password = "(?P<secret>\w+)"`),
+					regexp.MustCompile(`token`),
+				},
+				Group: "secret",
+			},
+			expected: []engine.Location{
+				{Filename: "main.py", Line: 1, Column: 13, EndLine: 1, EndColumn: 19, Offset: 12, EndOffset: 18},
+				{Filename: "main.py", Line: 2, Column: 1, EndLine: 2, EndColumn: 6, Offset: 20, EndOffset: 25},
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := tt.rule.RunFile(engine.NewFile("main.py", []byte(content)))
+			require.NoError(t, err)
+
+			var locations []engine.Location
+			for index := range findings {
+				locations = append(locations, findings[index].SourceLocation)
+			}
+
+			assert.Equal(t, tt.expected, locations)
+		})
+	}
+
+	t.Run("Should return an error for unknown groups", func(t *testing.T) {
+		rule := &Rule{Condition: &Regex{Expression: regexp.MustCompile(`(?P<secret>a)`), Group: "key"}}
+
+		_, err := rule.RunFile(engine.NewFile("main.py", []byte(content)))
+		assert.Error(t, err)
+	})
+}
+
+func TestRunFileWithInvalidCondition(t *testing.T) {
+	testcases := []struct {
+		name string
+		rule *Rule
+	}{
+		{
+			name: "Should return an error for a nil condition inside the tree",
+			rule: &Rule{Condition: &All{Conditions: []Condition{regex(`a`), nil}}},
+		},
+		{
+			name: "Should return an error for a regex without expression",
+			rule: &Rule{Condition: &Not{Condition: &Regex{}}},
+		},
+		{
+			name: "Should return an error for an invalid match type",
+			rule: &Rule{Type: MatchType(42), Expressions: []*regexp.Regexp{regexp.MustCompile(`a`)}},
+		},
+		{
+			name: "Should return an error for a condition with expressions",
+			rule: &Rule{Condition: regex(`a`), Expressions: []*regexp.Regexp{regexp.MustCompile(`b`)}},
+		},
+		{
+			name: "Should return an error for a condition with a scope",
+			rule: &Rule{Condition: &All{Conditions: []Condition{regex(`a`), regex(`b`)}}, Scope: LineScope},
+		},
+		{
+			name: "Should return an error for a condition with a group",
+			rule: &Rule{Condition: regex(`(?P<secret>a)`), Group: "secret"},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := tt.rule.RunFile(engine.NewFile("Main.java", []byte("a")))
+
+			assert.Error(t, err)
+			assert.Nil(t, findings)
+		})
+	}
+}
+
+func TestConditionOf(t *testing.T) {
+	expressions := []*regexp.Regexp{regexp.MustCompile(`a`), regexp.MustCompile(`b`)}
+
+	testcases := []struct {
+		name      string
+		matchType MatchType
+		expected  Condition
+	}{
+		{
+			name:      "Should map OrMatch into any of the expressions",
+			matchType: OrMatch,
+			expected:  &Any{Conditions: []Condition{&Regex{Expression: expressions[0]}, &Regex{Expression: expressions[1]}}},
+		},
+		{
+			name:      "Should map AndMatch into all of the expressions with first match only",
+			matchType: AndMatch,
+			expected: &All{
+				Conditions:     []Condition{&Regex{Expression: expressions[0]}, &Regex{Expression: expressions[1]}},
+				FirstMatchOnly: true,
+			},
+		},
+		{
+			name:      "Should map NotMatch into any of not of each expression",
+			matchType: NotMatch,
+			expected: &Any{Conditions: []Condition{
+				&Not{Condition: &Regex{Expression: expressions[0]}},
+				&Not{Condition: &Regex{Expression: expressions[1]}},
+			}},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := conditionOf(tt.matchType, expressions, "", nil, FileScope, 0)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, condition)
+		})
+	}
+}
@@ -16,43 +16,83 @@ package text
 
 import (
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
-)
+	"unicode/utf8"
 
-// regexNewLine regex representing the new line hexadecimal, equivalent of \n.
-var regexNewLine = regexp.MustCompile("\x0a")
+	engine "github.com/ZupIT/horusec-engine"
+)
 
 // File represents a file to be analyzed
 type File struct {
 	// AbsolutePath holds the complete path to the file (e.g. /home/user/myProject/router/handler.js)
-	AbsolutePath         string
-	RelativePath         string  // RelativePath holds the raw path relative to the root folder of the project
-	Content              []byte  // Content holds all the file content
-	Name                 string  // Name holds only the single name of the file (e.g. handler.js)
-	newlineIndexes       [][]int // newlineIndexes holds information about where is the beginning and ending of each line
-	newlineEndingIndexes []int   // newlineEndingIndexes represents the *start* index of each '\n' rune in the file
+	AbsolutePath string
+	RelativePath string // RelativePath holds the raw path relative to the root folder of the project
+	Content      []byte // Content holds all the file content
+	Name         string // Name holds only the single name of the file (e.g. handler.js)
+	lineStarts   []int  // lineStarts holds the index where each line begins, the first line always begins at 0
 }
 
 // NewTextFile create a new text file with all necessary info filled
 func NewTextFile(relativeFilePath string, content []byte) (*File, error) {
 	file := &File{
-		RelativePath:   relativeFilePath,
-		Content:        content,
-		Name:           filepath.Base(relativeFilePath),
-		newlineIndexes: regexNewLine.FindAllIndex(content, -1),
+		RelativePath: relativeFilePath,
+		Content:      content,
+		Name:         filepath.Base(relativeFilePath),
+		lineStarts:   findLineStarts(content),
 	}
 
 	if err := file.setAbsFilePath(); err != nil {
 		return nil, err
 	}
 
-	file.setNewlineEndingIndexes()
-
 	return file, nil
 }
 
+// findLineStarts returns the index where each line of the content begins. Lines can be ended by "\n", "\r\n" or a
+// lone "\r". A line ending at the end of the content is followed by an empty line
+func findLineStarts(content []byte) []int {
+	lineStarts := []int{0}
+
+	for index := 0; index < len(content); index++ {
+		if length := lineEndingLength(content, index); length > 0 {
+			index += length - 1
+			lineStarts = append(lineStarts, index+1)
+		}
+	}
+
+	return lineStarts
+}
+
+// lineEndingLength returns the length of the line ending starting at the index of the content, or 0 if there's none
+func lineEndingLength(content []byte, index int) int {
+	switch {
+	case content[index] == '\n':
+		return 1
+	case content[index] != '\r':
+		return 0
+	case index+1 < len(content) && content[index+1] == '\n':
+		return 2
+	default:
+		return 1
+	}
+}
+
+// textFileKey is the key used to share the text file between all text rules analyzing the same engine file
+type textFileKey struct{}
+
+// getTextFile returns the text file of the engine file, it will be created only by the first rule that needs it
+func getTextFile(file *engine.File) (*File, error) {
+	value, err := file.Value(textFileKey{}, func() (interface{}, error) {
+		return NewTextFile(file.Path, file.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*File), nil
+}
+
 // setAbsFilePath verifies if the filepath is absolute and set, otherwise it will parse and then set
 func (f *File) setAbsFilePath() error {
 	if filepath.IsAbs(f.RelativePath) {
@@ -67,81 +107,62 @@ func (f *File) setAbsFilePath() error {
 	return err
 }
 
-// setNewlineEndingIndexes for each new line index set the ending index
-func (f *File) setNewlineEndingIndexes() {
-	for _, newlineIndex := range f.newlineIndexes {
-		f.newlineEndingIndexes = append(f.newlineEndingIndexes, newlineIndex[0])
-	}
-}
-
-// nolint:funlen,wsl // todo complex function need to be improved
-// FindLineAndColumn get line and column using the beginning index of the example code
+// FindLineAndColumn get the line and column of the index in the file content. Both are 1-based, and the column is
+// counted in runes (e.g. the column of "b" in "ção b" is 5), so it matches what editors show to the users
 func (f *File) FindLineAndColumn(findingIndex int) (line, column int) {
-	// findingIndex is the index of the beginning of the text we want to
-	// locate inside the file
-
-	// newlineEndingIndexes holds the indexes of each \n in the file
-	// which means that each index in this slice is actually a line in the file
-
-	// so we search for where we would put the findingIndex in the array
-	// using a binary search algorithm, because this will give us the exact
-	// lines that the index is between.
-	lineIndex := f.binarySearch(findingIndex, f.newlineEndingIndexes)
-
-	// Now with the right index found we have to get the previous \n
-	// from the findingIndex, so it gets the right line
-	if lineIndex < len(f.newlineEndingIndexes) {
-		// we add +1 here because we want the line to
-		// reflect the "human" line count, not the indexed one in the slice
-		line = lineIndex + 1
-		endOfCurrentLine := lineIndex - 1
-
-		// If there is no previous line the finding is in the beginning
-		// of the file, so we just normalize to avoid signing issues (like -1 messing the indexing)
-		if endOfCurrentLine <= 0 {
-			endOfCurrentLine = 0
-		}
+	lineIndex := f.findLineIndex(findingIndex)
+	lineStart := f.lineStarts[lineIndex]
 
-		// now we access the textual index in the slice to ge the column
-		endOfCurrentLineInTheFile := f.newlineEndingIndexes[endOfCurrentLine]
+	return lineIndex + 1, utf8.RuneCount(f.Content[lineStart:f.clampIndex(findingIndex)]) + 1
+}
 
-		if lineIndex == 0 {
-			column = findingIndex
-		} else {
-			column = (findingIndex - 1) - endOfCurrentLineInTheFile
-		}
+// FindLocation get the location of the content between the start and end indexes, the end index is exclusive, as
+// the ones returned by regexp.FindIndex. The end line and column are the position right after the last character
+func (f *File) FindLocation(start, end int) engine.Location {
+	line, column := f.FindLineAndColumn(start)
+	endLine, endColumn := f.FindLineAndColumn(end)
+
+	return engine.Location{
+		Filename:  f.RelativePath,
+		Line:      line,
+		Column:    column,
+		EndLine:   endLine,
+		EndColumn: endColumn,
+		Offset:    f.clampIndex(start),
+		EndOffset: f.clampIndex(end),
 	}
-
-	return line, column
 }
 
-// binarySearch function uses this search algorithm to find the index of the matching element.
-func (f *File) binarySearch(searchIndex int, collection []int) (foundIndex int) {
-	foundIndex = sort.Search(
-		len(collection),
-		func(index int) bool { return collection[index] >= searchIndex },
-	)
+// ExtractSample get the content of the line where the index is, without the leading and trailing spaces
+func (f *File) ExtractSample(findingIndex int) string {
+	lineIndex := f.findLineIndex(findingIndex)
+	lineEnd := len(f.Content)
 
-	return
-}
+	if lineIndex+1 < len(f.lineStarts) {
+		lineEnd = f.lineStarts[lineIndex+1]
+	}
 
-// nolint:funlen // todo complex function, needs to be improved
-// ExtractSample search for the vulnerable code using the finding indexes
-func (f *File) ExtractSample(findingIndex int) string {
-	lineIndex := f.binarySearch(findingIndex, f.newlineEndingIndexes)
+	return strings.TrimSpace(string(f.Content[f.lineStarts[lineIndex]:lineEnd]))
+}
 
-	if lineIndex < len(f.newlineEndingIndexes) {
-		endOfPreviousLine := 0
+// findLineIndex uses a binary search to find the 0-based index of the line that contains the index of the content
+func (f *File) findLineIndex(index int) int {
+	index = f.clampIndex(index)
 
-		if lineIndex > 0 {
-			endOfPreviousLine = f.newlineEndingIndexes[lineIndex-1] + 1
-		}
+	return sort.Search(len(f.lineStarts), func(lineIndex int) bool {
+		return f.lineStarts[lineIndex] > index
+	}) - 1
+}
 
-		endOfCurrentLine := f.newlineEndingIndexes[lineIndex]
-		lineContent := f.Content[endOfPreviousLine:endOfCurrentLine]
+// clampIndex limits the index to the bounds of the content
+func (f *File) clampIndex(index int) int {
+	if index < 0 {
+		return 0
+	}
 
-		return strings.TrimSpace(string(lineContent))
+	if index > len(f.Content) {
+		return len(f.Content)
 	}
 
-	return ""
+	return index
 }
@@ -18,9 +18,12 @@ import (
 	"errors"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	engine "github.com/ZupIT/horusec-engine"
 )
 
 const (
@@ -116,21 +119,21 @@ func TestFindLineAndColumn(t *testing.T) {
 			regexExpression: `name\:`,
 			codeSample:      sampleKotlin,
 			expectedLine:    15,
-			expectedColumn:  70,
+			expectedColumn:  71,
 		},
 		{
 			name:            "Should success find line and column for go",
 			regexExpression: `cmd\.Short`,
 			codeSample:      sampleGo,
 			expectedLine:    26,
-			expectedColumn:  21,
+			expectedColumn:  22,
 		},
 		{
 			name:            "Should success find line and column for js",
 			regexExpression: `server\.listen`,
 			codeSample:      sampleJs,
 			expectedLine:    13,
-			expectedColumn:  2,
+			expectedColumn:  3,
 		},
 	}
 
@@ -201,7 +204,239 @@ func TestNewTextFile(t *testing.T) {
 		assert.Equalf(t, relativeFilePath, file.RelativePath, "failed to match relative path")
 		assert.Equalf(t, sampleGo, string(file.Content), "failed to match content")
 		assert.Equalf(t, filepath.Base(relativeFilePath), file.Name, "failed to match file name")
-		assert.Lenf(t, file.newlineIndexes, 30, "sample go contains 30 new line indexes")
-		assert.Lenf(t, file.newlineEndingIndexes, 30, "sample go contains 30 ending indexes")
+		assert.Lenf(t, file.lineStarts, 31, "sample go contains 31 lines")
 	})
 }
+
+func TestFindLineAndColumnPositions(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		index          int
+		expectedLine   int
+		expectedColumn int
+		expectedSample string
+	}{
+		{
+			name:           "Should find first column of the first line",
+			content:        "password = 1\nsecret = 2\n",
+			index:          0,
+			expectedLine:   1,
+			expectedColumn: 1,
+			expectedSample: "password = 1",
+		},
+		{
+			name:           "Should find column in the middle of the first line",
+			content:        "password = 1\nsecret = 2\n",
+			index:          9,
+			expectedLine:   1,
+			expectedColumn: 10,
+			expectedSample: "password = 1",
+		},
+		{
+			name:           "Should find first column of a middle line",
+			content:        "password = 1\nsecret = 2\ntoken = 3\n",
+			index:          13,
+			expectedLine:   2,
+			expectedColumn: 1,
+			expectedSample: "secret = 2",
+		},
+		{
+			name:           "Should find the new line character in its own line",
+			content:        "password = 1\nsecret = 2\n",
+			index:          12,
+			expectedLine:   1,
+			expectedColumn: 13,
+			expectedSample: "password = 1",
+		},
+		{
+			name:           "Should find last line without trailing new line",
+			content:        "password = 1\nsecret = 2",
+			index:          13,
+			expectedLine:   2,
+			expectedColumn: 1,
+			expectedSample: "secret = 2",
+		},
+		{
+			name:           "Should find single line without new line",
+			content:        "secret = 2",
+			index:          9,
+			expectedLine:   1,
+			expectedColumn: 10,
+			expectedSample: "secret = 2",
+		},
+		{
+			name:           "Should find empty line after trailing new line",
+			content:        "secret = 2\n",
+			index:          11,
+			expectedLine:   2,
+			expectedColumn: 1,
+			expectedSample: "",
+		},
+		{
+			name:           "Should find position in empty content",
+			content:        "",
+			index:          0,
+			expectedLine:   1,
+			expectedColumn: 1,
+			expectedSample: "",
+		},
+		{
+			name:           "Should find line with CRLF line endings",
+			content:        "password = 1\r\nsecret = 2\r\ntoken = 3",
+			index:          22,
+			expectedLine:   2,
+			expectedColumn: 9,
+			expectedSample: "secret = 2",
+		},
+		{
+			name:           "Should find last line with CRLF line endings",
+			content:        "password = 1\r\nsecret = 2\r\ntoken = 3",
+			index:          26,
+			expectedLine:   3,
+			expectedColumn: 1,
+			expectedSample: "token = 3",
+		},
+		{
+			name:           "Should find the carriage return character in its own line",
+			content:        "password = 1\r\nsecret = 2",
+			index:          13,
+			expectedLine:   1,
+			expectedColumn: 14,
+			expectedSample: "password = 1",
+		},
+		{
+			name:           "Should find line with lone CR line endings",
+			content:        "password = 1\rsecret = 2\rtoken = 3",
+			index:          24,
+			expectedLine:   3,
+			expectedColumn: 1,
+			expectedSample: "token = 3",
+		},
+		{
+			name:           "Should find line with mixed line endings",
+			content:        "a\nb\r\nc\rd",
+			index:          7,
+			expectedLine:   4,
+			expectedColumn: 1,
+			expectedSample: "d",
+		},
+		{
+			name:           "Should count empty lines",
+			content:        "\n\n\r\n\rsecret",
+			index:          5,
+			expectedLine:   5,
+			expectedColumn: 1,
+			expectedSample: "secret",
+		},
+		{
+			name:           "Should count columns in runes instead of bytes",
+			content:        "const ação = \"senha\"",
+			index:          16,
+			expectedLine:   1,
+			expectedColumn: 15,
+			expectedSample: "const ação = \"senha\"",
+		},
+		{
+			name:           "Should count columns in runes in lines after multi byte runes",
+			content:        "// 密码\n密码 := \"123\"",
+			index:          20,
+			expectedLine:   2,
+			expectedColumn: 7,
+			expectedSample: "密码 := \"123\"",
+		},
+		{
+			name:           "Should count tabs as a single column",
+			content:        "func main() {\n\t\tpassword := 1\n}",
+			index:          16,
+			expectedLine:   2,
+			expectedColumn: 3,
+			expectedSample: "password := 1",
+		},
+		{
+			name:           "Should limit index greater than content to the end of the content",
+			content:        "secret = 2",
+			index:          100,
+			expectedLine:   1,
+			expectedColumn: 11,
+			expectedSample: "secret = 2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			file, err := NewTextFile("test", []byte(testCase.content))
+			assert.NoError(t, err)
+
+			line, column := file.FindLineAndColumn(testCase.index)
+			assert.Equal(t, testCase.expectedLine, line, "failed to find correct line")
+			assert.Equal(t, testCase.expectedColumn, column, "failed to find correct column")
+			assert.Equal(t, testCase.expectedSample, file.ExtractSample(testCase.index), "failed to find code sample")
+		})
+	}
+}
+
+func TestFindLineStarts(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		expectedLineStarts []int
+	}{
+		{name: "Should return single line for empty content", content: "", expectedLineStarts: []int{0}},
+		{name: "Should split lines by LF", content: "a\nb\n", expectedLineStarts: []int{0, 2, 4}},
+		{name: "Should split lines by CRLF", content: "a\r\nb\r\n", expectedLineStarts: []int{0, 3, 6}},
+		{name: "Should split lines by lone CR", content: "a\rb\r", expectedLineStarts: []int{0, 2, 4}},
+		{name: "Should split lines by LF CR as two lines", content: "a\n\rb", expectedLineStarts: []int{0, 2, 3}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedLineStarts, findLineStarts([]byte(testCase.content)))
+		})
+	}
+}
+
+func TestFindLocation(t *testing.T) {
+	content := "func main() {\r\n\tpassword := \"ação\"\r\n\tquery := `SELECT *\n\t\tFROM users`\n}"
+
+	testCases := []struct {
+		name             string
+		match            string
+		expectedLocation engine.Location
+	}{
+		{
+			name:  "Should find location of a match in a single line",
+			match: "password := \"ação\"",
+			expectedLocation: engine.Location{
+				Filename: "main.go", Line: 2, Column: 2, EndLine: 2, EndColumn: 20, Offset: 16, EndOffset: 36,
+			},
+		},
+		{
+			name:  "Should find location of a match across lines",
+			match: "`SELECT *\n\t\tFROM users`",
+			expectedLocation: engine.Location{
+				Filename: "main.go", Line: 3, Column: 11, EndLine: 4, EndColumn: 14, Offset: 48, EndOffset: 71,
+			},
+		},
+		{
+			name:  "Should find location of a match ending at the end of the file",
+			match: "}",
+			expectedLocation: engine.Location{
+				Filename: "main.go", Line: 5, Column: 1, EndLine: 5, EndColumn: 2, Offset: 72, EndOffset: 73,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			file, err := NewTextFile("main.go", []byte(content))
+			assert.NoError(t, err)
+
+			start := strings.LastIndex(content, testCase.match)
+			location := file.FindLocation(start, start+len(testCase.match))
+			location.Filename = filepath.Base(location.Filename)
+
+			assert.Equal(t, testCase.expectedLocation, location)
+		})
+	}
+}
@@ -0,0 +1,1010 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/ZupIT/horusec-devkit/pkg/enums/confidence"
+	"github.com/ZupIT/horusec-devkit/pkg/enums/severities"
+	"gopkg.in/yaml.v3"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+const (
+	fieldRules       = "rules"
+	fieldID          = "id"
+	fieldName        = "name"
+	fieldSeverity    = "severity"
+	fieldConfidence  = "confidence"
+	fieldType        = "type"
+	fieldExpressions = "expressions"
+	fieldExtensions  = "extensions"
+	fieldCondition   = "condition"
+	fieldScope       = "scope"
+	fieldWindow      = "window"
+	fieldAnchor      = "anchor"
+	fieldGroup       = "group"
+	fieldDescription = "description"
+)
+
+// Operators of the conditions in the rules files
+const (
+	operatorAll   = "all"
+	operatorAny   = "any"
+	operatorNot   = "not"
+	operatorRegex = "regex"
+)
+
+var (
+	// ErrDuplicatedRuleID is returned when more than one rule is loaded with the same ID
+	ErrDuplicatedRuleID = errors.New("duplicated rule ID")
+
+	// ErrRequiredField is returned when a required field of a rule is missing or empty
+	ErrRequiredField = errors.New("required field")
+
+	// ErrUnknownField is returned when a rules file has a field that is not known by the loader
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrInvalidValue is returned when a field of a rule has an invalid value
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ruleFileExtensions are the extensions of the files loaded when walking a directory of rules
+var ruleFileExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// matchTypes maps the match types names used by the rules files into the match types
+var matchTypes = map[string]MatchType{
+	"or":      OrMatch,
+	"regular": Regular,
+	"not":     NotMatch,
+	"and":     AndMatch,
+}
+
+// scopeTypes maps the scope names used by the rules files into the scope types
+var scopeTypes = map[string]ScopeType{
+	"file":   FileScope,
+	"line":   LineScope,
+	"window": WindowScope,
+	"block":  BlockScope,
+	"indent": IndentScope,
+}
+
+// conditionModifiers maps the fields of the condition nodes that modify an operator into the operator
+var conditionModifiers = map[string]string{
+	fieldScope:  operatorAll,
+	fieldWindow: operatorAll,
+	fieldAnchor: operatorNot,
+	fieldGroup:  operatorRegex,
+}
+
+// LoadError is returned when a rules file can't be loaded, it contains the location of the problem
+type LoadError struct {
+	File   string // File is the path of the rules file
+	Line   int    // Line is the line of the problem in the file, or 0 when it's unknown
+	RuleID string // RuleID is the ID of the rule with the problem, if it's known
+	Field  string // Field is the field with the problem, like expressions[1], if any
+	Err    error
+}
+
+// Error returns the problem prefixed by its location, like "rules.yaml:12: HS-JAVA-1: expressions[1]: cause"
+func (e *LoadError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+
+	for _, part := range []string{e.RuleID, e.Field} {
+		if part != "" {
+			location += ": " + part
+		}
+	}
+
+	return fmt.Sprintf("%s: %v", location, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// newInvalidValueError creates the error of the invalid value of the node of the field, the message formatted with
+// the format and the arguments describes the problem, like the expected values
+func newInvalidValueError(node *yaml.Node, field, format string, args ...interface{}) *LoadError {
+	return &LoadError{
+		Line: node.Line, Field: field,
+		Err: fmt.Errorf("%w: %s", ErrInvalidValue, fmt.Sprintf(format, args...)),
+	}
+}
+
+// ExpressionError is returned when a regular expression of a rule is invalid. Position holds the byte offset of the
+// invalid part of the expression, or -1 when it's unknown
+type ExpressionError struct {
+	Expression string
+	Position   int
+	Err        error
+}
+
+// Error returns the cause of the error with the position of the invalid part of the expression
+func (e *ExpressionError) Error() string {
+	if e.Position < 0 {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("offset %d: %v", e.Position, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// ruleDefinition is the declarative definition of a rule in a rules file
+type ruleDefinition struct {
+	engine.Metadata
+	Type        string
+	Expressions []string
+	Extensions  []string
+	Condition   yaml.Node
+	Scope       yaml.Node
+	Window      yaml.Node
+	Anchor      yaml.Node
+	Group       string
+}
+
+// fields maps the name of each field in the rules files into the attribute of the definition that holds its value
+func (d *ruleDefinition) fields() map[string]interface{} {
+	fields := d.metadataFields()
+	fields[fieldType] = &d.Type
+	fields[fieldExpressions] = &d.Expressions
+	fields[fieldExtensions] = &d.Extensions
+	fields[fieldCondition] = &d.Condition
+	fields[fieldScope] = &d.Scope
+	fields[fieldWindow] = &d.Window
+	fields[fieldAnchor] = &d.Anchor
+	fields[fieldGroup] = &d.Group
+
+	return fields
+}
+
+// metadataFields maps the name of each metadata field in the rules files into the attribute of the definition
+func (d *ruleDefinition) metadataFields() map[string]interface{} {
+	return map[string]interface{}{
+		fieldID:          &d.ID,
+		fieldName:        &d.Name,
+		fieldDescription: &d.Description,
+		fieldSeverity:    &d.Severity,
+		fieldConfidence:  &d.Confidence,
+		"cwes":           &d.CWEs,
+		"cves":           &d.CVEs,
+		"mitigation":     &d.Mitigation,
+		"reference":      &d.Reference,
+		"safeExample":    &d.SafeExample,
+		"unsafeExample":  &d.UnsafeExample,
+	}
+}
+
+// ruleLoader loads rules from many files, keeping where each rule ID was defined to reject duplicated IDs
+type ruleLoader struct {
+	rules []*Rule
+	seen  map[string]string
+}
+
+func newRuleLoader() *ruleLoader {
+	return &ruleLoader{seen: map[string]string{}}
+}
+
+// LoadRules loads the rules defined in YAML or JSON files. Each path can be a file, which is loaded independently of
+// its extension, or a directory, in which all .yaml, .yml and .json files are loaded recursively in lexical order.
+// A rules file contains a list of rules, or a mapping with this list in the rules field, like:
+//
+//	rules:
+//	  - id: HS-JAVA-1
+//	    name: Weak hash
+//	    description: MD5 is a weak hash algorithm
+//	    severity: MEDIUM
+//	    confidence: HIGH
+//	    cwes: [CWE-327]
+//	    type: or
+//	    expressions:
+//	      - MessageDigest\.getInstance\("MD5"\)
+//	    extensions: [.java]
+//
+// The id, name, severity and expressions fields are required, type can be or (default), and, not or regular.
+// Rules of the and type can have a scope, which is file (default), line, window, block or indent, and the number of
+// lines before and after the match of the window scope in the window field, see ScopeType for their semantics.
+// Rules of the not type can have an anchor expression, whose first match is reported as the location of the findings,
+// instead of reporting the whole file. The group field is the name or number of the capture group of the expressions
+// that is reported as the location of the findings, and the name and description can interpolate the named groups
+// of the match, like "Hard-coded key for {{.service}}".
+// Instead of type and expressions, a rule can have a condition, which is an expression tree of all, any, not and regex
+// nodes, see Condition for their semantics:
+//
+//	condition:
+//	  all:
+//	    - regex: new Random\(\)
+//	    - not:
+//	        regex: import java\.security\.SecureRandom
+//
+// The all operator can also have the scope and window fields, like the rules of the and type, the not operator can
+// have the anchor field, like the rules of the not type, and the regex operator can have the group field.
+// Any problem in the files is returned as a *LoadError, and the loading stops at the first one
+func LoadRules(paths ...string) ([]engine.Rule, error) {
+	loader := newRuleLoader()
+
+	for _, path := range paths {
+		if err := loader.loadPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	rules := make([]engine.Rule, 0, len(loader.rules))
+	for _, rule := range loader.rules {
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ParseRules parses the rules defined in the content of a YAML or JSON rules file, filename is only used to identify
+// the file in the errors. See LoadRules for the format of the file
+func ParseRules(filename string, content []byte) ([]*Rule, error) {
+	loader := newRuleLoader()
+
+	if err := loader.parse(filename, content); err != nil {
+		return nil, err
+	}
+
+	return loader.rules, nil
+}
+
+// loadPath loads the rules file, or all rules files inside the directory
+func (l *ruleLoader) loadPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return &LoadError{File: path, Err: err}
+	}
+
+	if info.IsDir() {
+		return l.loadDir(path)
+	}
+
+	return l.loadFile(path)
+}
+
+// loadDir loads all rules files inside the directory and its subdirectories
+func (l *ruleLoader) loadDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return &LoadError{File: path, Err: err}
+		}
+
+		if entry.IsDir() || !ruleFileExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		return l.loadFile(path)
+	})
+}
+
+// loadFile reads and parses the rules file
+func (l *ruleLoader) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return &LoadError{File: path, Err: err}
+	}
+
+	return l.parse(path, content)
+}
+
+// parse parses the content of the rules file and adds its rules to the loader
+func (l *ruleLoader) parse(filename string, content []byte) error {
+	rulesNode, err := decodeRulesNode(filename, content)
+	if err != nil {
+		return err
+	}
+
+	for _, ruleNode := range rulesNode.Content {
+		if err := l.parseRule(filename, ruleNode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeRulesNode decodes the content of the rules file returning the node with the list of rules, which is empty for
+// empty files
+func decodeRulesNode(filename string, content []byte) (*yaml.Node, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, &LoadError{File: filename, Err: err}
+	}
+
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.SequenceNode}, nil
+	}
+
+	return getRulesNode(filename, document.Content[0])
+}
+
+// getRulesNode returns the node with the list of rules, which can be the root node or its rules field
+func getRulesNode(filename string, root *yaml.Node) (*yaml.Node, error) {
+	if root.Kind == yaml.SequenceNode {
+		return root, nil
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, newRulesListError(filename, root, "")
+	}
+
+	return getRulesField(filename, root)
+}
+
+// getRulesField returns the list of rules of the rules field of the mapping, which is the only field allowed in it
+func getRulesField(filename string, root *yaml.Node) (*yaml.Node, error) {
+	rulesNode := yaml.Node{Kind: yaml.SequenceNode}
+
+	if _, err := decodeFields(root, map[string]interface{}{fieldRules: &rulesNode}); err != nil {
+		err.File = filename
+
+		return nil, err
+	}
+
+	if rulesNode.Kind != yaml.SequenceNode {
+		return nil, newRulesListError(filename, &rulesNode, fieldRules)
+	}
+
+	return &rulesNode, nil
+}
+
+// newRulesListError creates the error of a node of the rules file that should be a list of rules
+func newRulesListError(filename string, node *yaml.Node, field string) *LoadError {
+	err := newInvalidValueError(node, field, "expected a list of rules")
+	err.File = filename
+
+	return err
+}
+
+// parseRule decodes the rule definition from the node, validates it and adds the rule to the loader
+func (l *ruleLoader) parseRule(filename string, node *yaml.Node) error {
+	rule, idNode, err := parseRuleNode(node)
+	if err != nil {
+		err.File = filename
+
+		return err
+	}
+
+	return l.addRule(rule, filename, idNode.Line)
+}
+
+// addRule adds the rule defined in the line of the rules file to the loader, unless its ID was already defined
+func (l *ruleLoader) addRule(rule *Rule, filename string, line int) error {
+	if previous, ok := l.seen[rule.ID]; ok {
+		return &LoadError{
+			File: filename, Line: line, RuleID: rule.ID, Field: fieldID,
+			Err: fmt.Errorf("%w, already defined at %s", ErrDuplicatedRuleID, previous),
+		}
+	}
+
+	l.seen[rule.ID] = fmt.Sprintf("%s:%d", filename, line)
+	l.rules = append(l.rules, rule)
+
+	return nil
+}
+
+// parseRuleNode decodes the rule definition from the node and creates the rule from it, returning also the node of
+// the rule ID, which is used to report duplicated IDs
+func parseRuleNode(node *yaml.Node) (*Rule, *yaml.Node, *LoadError) {
+	definition, values, err := decodeRuleDefinition(node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rule, err := definition.toRule(node, values)
+	if err != nil {
+		err.RuleID = definition.ID
+
+		return nil, nil, err
+	}
+
+	return rule, values[fieldID], nil
+}
+
+// decodeRuleDefinition decodes each field of the rule definition from the node, returning also the node of each
+// field value, which are used to find the line of the problems found when validating the definition
+func decodeRuleDefinition(node *yaml.Node) (*ruleDefinition, map[string]*yaml.Node, *LoadError) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil, newInvalidValueError(node, "", "expected a rule")
+	}
+
+	definition := new(ruleDefinition)
+
+	values, err := decodeFields(node, definition.fields())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return definition, values, nil
+}
+
+// decodeFields decodes the value of each field of the mapping node into its attribute in fields, returning the node
+// of each field value
+func decodeFields(node *yaml.Node, fields map[string]interface{}) (map[string]*yaml.Node, *LoadError) {
+	values := map[string]*yaml.Node{}
+
+	for index := 0; index < len(node.Content); index += 2 {
+		key, value := node.Content[index], node.Content[index+1]
+		if err := decodeField(fields, key, value); err != nil {
+			return nil, err
+		}
+
+		values[key.Value] = value
+	}
+
+	return values, nil
+}
+
+// decodeField decodes the value of the field named by the key into its attribute in fields
+func decodeField(fields map[string]interface{}, key, value *yaml.Node) *LoadError {
+	target, ok := fields[key.Value]
+	if !ok {
+		return &LoadError{Line: key.Line, Field: key.Value, Err: ErrUnknownField}
+	}
+
+	if err := value.Decode(target); err != nil {
+		return newInvalidValueError(value, key.Value, "%v", err)
+	}
+
+	return nil
+}
+
+// toRule validates the rule definition and creates the rule from it, with its condition or with its type and
+// expressions
+func (d *ruleDefinition) toRule(node *yaml.Node, values map[string]*yaml.Node) (*Rule, *LoadError) {
+	if err := d.validate(node, values); err != nil {
+		return nil, err
+	}
+
+	if values[fieldCondition] != nil {
+		return d.toConditionRule(values)
+	}
+
+	return d.toExpressionsRule(values)
+}
+
+// validate checks the fields shared by all rules, which are the required fields, the severity and the confidence
+func (d *ruleDefinition) validate(node *yaml.Node, values map[string]*yaml.Node) *LoadError {
+	if err := checkRequiredFields(node, values); err != nil {
+		return err
+	}
+
+	return d.normalizeSeverityAndConfidence(values)
+}
+
+// checkRequiredFields checks if the required fields are set, a rule must have either expressions or a condition
+func checkRequiredFields(node *yaml.Node, values map[string]*yaml.Node) *LoadError {
+	required := []string{fieldID, fieldName, fieldSeverity}
+	if values[fieldCondition] == nil {
+		required = append(required, fieldExpressions)
+	}
+
+	for _, field := range required {
+		if isEmptyNode(values[field]) {
+			return &LoadError{Line: node.Line, Field: field, Err: ErrRequiredField}
+		}
+	}
+
+	return nil
+}
+
+// isEmptyNode checks if the node of a field is not set or has an empty value
+func isEmptyNode(node *yaml.Node) bool {
+	return node == nil || (node.Value == "" && len(node.Content) == 0)
+}
+
+// normalizeSeverityAndConfidence validates the severity and confidence, which are converted into upper case
+func (d *ruleDefinition) normalizeSeverityAndConfidence(values map[string]*yaml.Node) *LoadError {
+	d.Severity, d.Confidence = strings.ToUpper(d.Severity), strings.ToUpper(d.Confidence)
+
+	if !severities.Contains(d.Severity) {
+		return newInvalidValueError(values[fieldSeverity], fieldSeverity,
+			"%q, expected one of %v", d.Severity, severities.Values())
+	}
+
+	if d.Confidence != "" && !isConfidence(d.Confidence) {
+		return newInvalidValueError(values[fieldConfidence], fieldConfidence,
+			"%q, expected one of %v", d.Confidence, confidence.Values())
+	}
+
+	return nil
+}
+
+// isConfidence checks if the value is one of the confidence levels
+func isConfidence(value string) bool {
+	for _, level := range confidence.Values() {
+		if level.ToString() == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toExpressionsRule creates the rule with the type and the expressions of the definition, and the fields that modify
+// how they match
+func (d *ruleDefinition) toExpressionsRule(values map[string]*yaml.Node) (*Rule, *LoadError) {
+	rule := &Rule{Metadata: d.Metadata, Extensions: normalizeExtensions(d.Extensions), Group: d.Group}
+
+	var err *LoadError
+
+	if rule.Type, err = parseMatchType(d.Type, values[fieldType]); err != nil {
+		return nil, err
+	}
+
+	if rule.Expressions, err = compileExpressions(d.Expressions, values[fieldExpressions]); err != nil {
+		return nil, err
+	}
+
+	return rule, parseModifiers(rule, values)
+}
+
+// parseMatchType parses the name of the match type of the node, which is OrMatch when it's not set
+func parseMatchType(name string, node *yaml.Node) (MatchType, *LoadError) {
+	matchType, ok := matchTypes[strings.ToLower(name)]
+	if name != "" && !ok {
+		return OrMatch, newInvalidValueError(node, fieldType, "%q, expected one of or, and, not or regular", name)
+	}
+
+	return matchType, nil
+}
+
+// compileExpressions compiles the regular expressions, the errors contain the line of the invalid expression and the
+// position of the problem inside it
+func compileExpressions(expressions []string, node *yaml.Node) ([]*regexp.Regexp, *LoadError) {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+
+	for index, expression := range expressions {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			field := fmt.Sprintf("%s[%d]", fieldExpressions, index)
+
+			return nil, &LoadError{Line: node.Content[index].Line, Field: field, Err: newExpressionError(expression, err)}
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// parseModifiers parses the fields that modify how the expressions of the rule match, which are the group of the
+// expressions and the fields that depend on the match type
+func parseModifiers(rule *Rule, values map[string]*yaml.Node) *LoadError {
+	if err := validateGroup(rule, values[fieldGroup]); err != nil {
+		return err
+	}
+
+	return parseTypeModifiers(rule, values)
+}
+
+// validateGroup checks if at least one of the expressions has the group of the rule
+func validateGroup(rule *Rule, node *yaml.Node) *LoadError {
+	if rule.Group == "" {
+		return nil
+	}
+
+	for _, expression := range rule.Expressions {
+		if _, ok := groupIndex(expression, rule.Group); ok {
+			return nil
+		}
+	}
+
+	return newInvalidValueError(node, fieldGroup, "none of the expressions has the group %q", rule.Group)
+}
+
+// parseTypeModifiers parses the fields that modify the match type of the rule, which are the scope and window of the
+// and type, and the anchor of the not type
+func parseTypeModifiers(rule *Rule, values map[string]*yaml.Node) *LoadError {
+	if err := checkTypeModifiers(rule.Type, values); err != nil {
+		return err
+	}
+
+	var err *LoadError
+
+	if rule.Scope, rule.Window, err = parseScope(values[fieldScope], values[fieldWindow], ""); err != nil {
+		return err
+	}
+
+	rule.Anchor, err = parseAnchor(values[fieldAnchor], fieldAnchor)
+
+	return err
+}
+
+// checkTypeModifiers checks if the fields that modify a match type are only used with their match type
+func checkTypeModifiers(matchType MatchType, values map[string]*yaml.Node) *LoadError {
+	modifiers := []struct {
+		field     string
+		matchType MatchType
+	}{{fieldScope, AndMatch}, {fieldWindow, AndMatch}, {fieldAnchor, NotMatch}}
+
+	for _, modifier := range modifiers {
+		if node := values[modifier.field]; node != nil && matchType != modifier.matchType {
+			return newInvalidValueError(node, modifier.field, "%s can't be used with this type", modifier.field)
+		}
+	}
+
+	return nil
+}
+
+// toConditionRule creates the rule with the condition of the definition
+func (d *ruleDefinition) toConditionRule(values map[string]*yaml.Node) (*Rule, *LoadError) {
+	if err := checkConditionConflicts(values); err != nil {
+		return nil, err
+	}
+
+	condition, err := parseCondition(values[fieldCondition], fieldCondition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rule{Metadata: d.Metadata, Condition: condition, Extensions: normalizeExtensions(d.Extensions)}, nil
+}
+
+// checkConditionConflicts checks that the rule with a condition doesn't have the type, the expressions and the fields
+// that modify them, since they would be ignored
+func checkConditionConflicts(values map[string]*yaml.Node) *LoadError {
+	for _, field := range []string{fieldType, fieldExpressions, fieldScope, fieldWindow, fieldAnchor, fieldGroup} {
+		if node := values[field]; node != nil {
+			return newInvalidValueError(node, field, "%s can't be used with %s", field, fieldCondition)
+		}
+	}
+
+	return nil
+}
+
+// conditionNode is a condition in a rules file, with the key and the value of its operator and the nodes of the
+// fields that modify it, like the scope of the all operator
+type conditionNode struct {
+	operator  *yaml.Node
+	value     *yaml.Node
+	modifiers map[string]*yaml.Node
+}
+
+// conditionParser parses the value of an operator with the fields that modify it, field is the path of the condition
+type conditionParser func(value *yaml.Node, modifiers map[string]*yaml.Node, field string) (Condition, *LoadError)
+
+// parseCondition parses the condition node, which is a mapping with one of the all, any, not or regex operators,
+// and the fields that modify it. The field is the path of the node in the rule, like condition.all[1].not, which is
+// used in the errors
+func parseCondition(node *yaml.Node, field string) (Condition, *LoadError) {
+	condition, err := splitConditionNode(node, field)
+	if err != nil {
+		return nil, err
+	}
+
+	parse := conditionParserOf(condition.operator.Value)
+	if parse == nil {
+		return nil, &LoadError{
+			Line: condition.operator.Line, Field: joinField(field, condition.operator.Value), Err: ErrUnknownField,
+		}
+	}
+
+	return parse(condition.value, condition.modifiers, field)
+}
+
+// conditionParserOf returns the parser of the operator, or nil when the operator is unknown
+func conditionParserOf(operator string) conditionParser {
+	switch operator {
+	case operatorAll:
+		return parseAll
+	case operatorAny:
+		return parseAny
+	case operatorNot:
+		return parseNot
+	case operatorRegex:
+		return parseRegex
+	}
+
+	return nil
+}
+
+// splitConditionNode splits the fields of the condition node into its operator and the fields that modify it,
+// checking if there is exactly one operator and if the modifiers can be used with it
+func splitConditionNode(node *yaml.Node, field string) (*conditionNode, *LoadError) {
+	condition, ok := newConditionNode(node)
+	if !ok {
+		return nil, newInvalidValueError(node, field, "expected a mapping with one of all, any, not or regex")
+	}
+
+	return condition, condition.checkModifiers(node, field)
+}
+
+// newConditionNode creates the condition of the node, it fails when the node is not a mapping or when it doesn't
+// have exactly one operator
+func newConditionNode(node *yaml.Node) (*conditionNode, bool) {
+	if node.Kind != yaml.MappingNode {
+		return nil, false
+	}
+
+	condition := &conditionNode{modifiers: map[string]*yaml.Node{}}
+
+	for index := 0; index < len(node.Content); index += 2 {
+		if !condition.add(node.Content[index], node.Content[index+1]) {
+			return nil, false
+		}
+	}
+
+	return condition, condition.operator != nil
+}
+
+// add adds the field of the key to the condition as its operator or as a modifier, it fails when the condition
+// already has an operator
+func (c *conditionNode) add(key, value *yaml.Node) bool {
+	switch {
+	case conditionModifiers[key.Value] != "":
+		c.modifiers[key.Value] = value
+	case c.operator != nil:
+		return false
+	default:
+		c.operator, c.value = key, value
+	}
+
+	return true
+}
+
+// checkModifiers checks if the modifiers of the condition, in the order they appear in the node, can be used with its
+// operator
+func (c *conditionNode) checkModifiers(node *yaml.Node, field string) *LoadError {
+	for index := 0; index < len(node.Content); index += 2 {
+		modifier := node.Content[index].Value
+		if operator := conditionModifiers[modifier]; operator != "" && operator != c.operator.Value {
+			return newInvalidValueError(node, field, "%s can only be used with %s", modifier, operator)
+		}
+	}
+
+	return nil
+}
+
+// parseAll parses the conditions and the scope of the all operator
+func parseAll(value *yaml.Node, modifiers map[string]*yaml.Node, field string) (Condition, *LoadError) {
+	conditions, err := parseConditions(value, joinField(field, operatorAll))
+	if err != nil {
+		return nil, err
+	}
+
+	scope, window, err := parseScope(modifiers[fieldScope], modifiers[fieldWindow], field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &All{Conditions: conditions, Scope: scope, Window: window}, nil
+}
+
+// parseAny parses the conditions of the any operator, which has no modifiers
+func parseAny(value *yaml.Node, _ map[string]*yaml.Node, field string) (Condition, *LoadError) {
+	conditions, err := parseConditions(value, joinField(field, operatorAny))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Any{Conditions: conditions}, nil
+}
+
+// parseNot parses the condition and the anchor of the not operator
+func parseNot(value *yaml.Node, modifiers map[string]*yaml.Node, field string) (Condition, *LoadError) {
+	condition, err := parseCondition(value, joinField(field, operatorNot))
+	if err != nil {
+		return nil, err
+	}
+
+	anchor, err := parseAnchor(modifiers[fieldAnchor], joinField(field, fieldAnchor))
+	if err != nil {
+		return nil, err
+	}
+
+	return newNot(condition, anchor), nil
+}
+
+// parseAnchor compiles the anchor expression of the node, the anchor is nil when the node is not set
+func parseAnchor(node *yaml.Node, field string) (*regexp.Regexp, *LoadError) {
+	if node == nil {
+		return nil, nil
+	}
+
+	return compileRegex(node, field)
+}
+
+// parseRegex parses the regular expression and the group of the regex operator
+func parseRegex(value *yaml.Node, modifiers map[string]*yaml.Node, field string) (Condition, *LoadError) {
+	re, err := compileRegex(value, joinField(field, operatorRegex))
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := parseGroup(re, modifiers[fieldGroup], joinField(field, fieldGroup))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Regex{Expression: re, Group: group}, nil
+}
+
+// parseGroup parses the group of the node, which must be a capture group of the expression, the group is empty when
+// the node is not set
+func parseGroup(re *regexp.Regexp, node *yaml.Node, field string) (string, *LoadError) {
+	if node == nil {
+		return "", nil
+	}
+
+	if _, ok := groupIndex(re, node.Value); !ok || node.Kind != yaml.ScalarNode {
+		return "", newInvalidValueError(node, field, "the expression has no group %q", node.Value)
+	}
+
+	return node.Value, nil
+}
+
+// parseScope parses the scope and window nodes, which can be nil when they are not set. The window is required by
+// the window scope, and can't be used by the others. The field is the path of the node that has them
+func parseScope(scopeNode, windowNode *yaml.Node, field string) (ScopeType, int, *LoadError) {
+	scope, err := parseScopeType(scopeNode, joinField(field, fieldScope))
+	if err != nil {
+		return FileScope, 0, err
+	}
+
+	window, err := parseWindow(windowNode, scope, joinField(field, fieldWindow))
+	if err != nil {
+		return FileScope, 0, err
+	}
+
+	if scope == WindowScope && windowNode == nil {
+		return FileScope, 0, &LoadError{Line: scopeNode.Line, Field: joinField(field, fieldWindow), Err: ErrRequiredField}
+	}
+
+	return scope, window, nil
+}
+
+// parseScopeType parses the name of the scope of the node, which is FileScope when the node is not set
+func parseScopeType(node *yaml.Node, field string) (ScopeType, *LoadError) {
+	if node == nil {
+		return FileScope, nil
+	}
+
+	scope, ok := scopeTypes[strings.ToLower(node.Value)]
+	if !ok || node.Kind != yaml.ScalarNode {
+		return FileScope, newInvalidValueError(node, field,
+			"%q, expected one of file, line, window, block or indent", node.Value)
+	}
+
+	return scope, nil
+}
+
+// parseWindow parses the number of lines of the window of the node, which can only be used with the window scope
+func parseWindow(node *yaml.Node, scope ScopeType, field string) (int, *LoadError) {
+	if node == nil {
+		return 0, nil
+	}
+
+	var window int
+
+	if err := node.Decode(&window); err != nil || window <= 0 || scope != WindowScope {
+		return 0, newInvalidValueError(node, field, "expected a number of lines greater than zero, with the window scope")
+	}
+
+	return window, nil
+}
+
+// joinField joins the path of a node in the rule with the name of its field
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// parseConditions parses the list of conditions of the all and any operators, which can't be empty
+func parseConditions(node *yaml.Node, field string) ([]Condition, *LoadError) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, newInvalidValueError(node, field, "expected a list of conditions")
+	}
+
+	if len(node.Content) == 0 {
+		return nil, &LoadError{Line: node.Line, Field: field, Err: ErrRequiredField}
+	}
+
+	return parseConditionsList(node.Content, field)
+}
+
+// parseConditionsList parses each one of the conditions of the list, field is the path of the list in the rule
+func parseConditionsList(nodes []*yaml.Node, field string) ([]Condition, *LoadError) {
+	conditions := make([]Condition, 0, len(nodes))
+
+	for index, node := range nodes {
+		condition, err := parseCondition(node, fmt.Sprintf("%s[%d]", field, index))
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+// compileRegex compiles the regular expression of the node, like the ones of the regex operator and of the anchors
+func compileRegex(node *yaml.Node, field string) (*regexp.Regexp, *LoadError) {
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return nil, newInvalidValueError(node, field, "expected a regular expression")
+	}
+
+	re, err := regexp.Compile(node.Value)
+	if err != nil {
+		return nil, &LoadError{Line: node.Line, Field: field, Err: newExpressionError(node.Value, err)}
+	}
+
+	return re, nil
+}
+
+// newExpressionError creates the error of an invalid expression, finding the position of the invalid part of the
+// expression reported by the regexp syntax error
+func newExpressionError(expression string, err error) *ExpressionError {
+	position := -1
+
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		position = syntaxErrorPosition(expression, syntaxErr)
+	}
+
+	return &ExpressionError{Expression: expression, Position: position, Err: err}
+}
+
+// syntaxErrorPosition returns the byte offset of the invalid part of the expression reported by the syntax error, or
+// -1 when the error reports the whole expression, like the errors of unbalanced parentheses. The invalid part of a
+// missing bracket goes until the end of the expression
+func syntaxErrorPosition(expression string, syntaxErr *syntax.Error) int {
+	switch {
+	case syntaxErr.Expr == "" || syntaxErr.Expr == expression:
+		return -1
+	case syntaxErr.Code == syntax.ErrMissingBracket:
+		return strings.LastIndex(expression, syntaxErr.Expr)
+	}
+
+	return strings.Index(expression, syntaxErr.Expr)
+}
+
+// normalizeExtensions adds the leading dot of the extensions that don't have it
+func normalizeExtensions(extensions []string) []string {
+	var normalized []string
+
+	for _, extension := range extensions {
+		if extension != engine.AcceptAnyExtension && !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+
+		normalized = append(normalized, extension)
+	}
+
+	return normalized
+}
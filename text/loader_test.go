@@ -0,0 +1,468 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+const weakHashRuleYAML = `rules:
+  - id: HS-JAVA-1
+    name: Weak hash
+    description: MD5 is a weak hash algorithm
+    severity: medium
+    confidence: high
+    cwes: [CWE-327]
+    cves: [CVE-2004-2761]
+    mitigation: Use SHA-256
+    reference: https://cwe.mitre.org/data/definitions/327.html
+    safeExample: MessageDigest.getInstance("SHA-256")
+    unsafeExample: MessageDigest.getInstance("MD5")
+    type: and
+    expressions:
+      - MessageDigest\.getInstance\("MD5"\)
+      - import java\.security
+    extensions: [.java, kt]
+`
+
+const hardcodedSecretRuleJSON = `[
+	{
+		"id": "HS-LEAKS-1",
+		"name": "Hardcoded secret",
+		"severity": "CRITICAL",
+		"expressions": ["(?i)secret\\s*=\\s*\"[^\"]+\""]
+	}
+]
+`
+
+func writeRulesFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+// nolint:funlen // table of assertions of all fields of the parsed rules
+func TestParseRules(t *testing.T) {
+	t.Run("Should parse all fields of a YAML rule", func(t *testing.T) {
+		rules, err := ParseRules("rules.yaml", []byte(weakHashRuleYAML))
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+
+		assert.Equal(t, engine.Metadata{
+			ID:            "HS-JAVA-1",
+			Name:          "Weak hash",
+			Description:   "MD5 is a weak hash algorithm",
+			Severity:      "MEDIUM",
+			Confidence:    "HIGH",
+			CWEs:          []string{"CWE-327"},
+			CVEs:          []string{"CVE-2004-2761"},
+			Mitigation:    "Use SHA-256",
+			Reference:     "https://cwe.mitre.org/data/definitions/327.html",
+			SafeExample:   `MessageDigest.getInstance("SHA-256")`,
+			UnsafeExample: `MessageDigest.getInstance("MD5")`,
+		}, rules[0].Metadata)
+		assert.Equal(t, AndMatch, rules[0].Type)
+		assert.Equal(t, []string{".java", ".kt"}, rules[0].Extensions)
+		require.Len(t, rules[0].Expressions, 2)
+		assert.Equal(t, `MessageDigest\.getInstance\("MD5"\)`, rules[0].Expressions[0].String())
+	})
+
+	t.Run("Should parse a JSON list of rules with the default match type", func(t *testing.T) {
+		rules, err := ParseRules("rules.json", []byte(hardcodedSecretRuleJSON))
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+
+		assert.Equal(t, "HS-LEAKS-1", rules[0].ID)
+		assert.Equal(t, OrMatch, rules[0].Type)
+		assert.Empty(t, rules[0].Extensions)
+		assert.True(t, rules[0].Expressions[0].MatchString(`SECRET = "abc"`))
+	})
+
+	t.Run("Should parse the condition of a rule", func(t *testing.T) {
+		content := "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    all:\n      - any:\n" +
+			"          - regex: 'new Random\\('\n          - regex: 'Math\\.random\\('\n" +
+			"      - not:\n          regex: SecureRandom\n"
+
+		rules, err := ParseRules("rules.yaml", []byte(content))
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Empty(t, rules[0].Expressions)
+
+		findings, err := rules[0].RunFile(engine.NewFile("A.java", []byte("int a = Math.random();")))
+		assert.NoError(t, err)
+		assert.Len(t, findings, 1)
+
+		findings, err = rules[0].RunFile(engine.NewFile("A.java", []byte("SecureRandom r;\nnew Random();")))
+		assert.NoError(t, err)
+		assert.Empty(t, findings)
+	})
+
+	t.Run("Should parse the scope of rules of the and type and of all conditions", func(t *testing.T) {
+		content := "- id: HS-1\n  name: Test\n  severity: LOW\n  type: and\n  scope: window\n  window: 3\n" +
+			"  expressions: [a, b]\n" +
+			"- id: HS-2\n  name: Test\n  severity: LOW\n  condition:\n    all: [{regex: a}, {regex: b}]\n" +
+			"    scope: Block\n"
+
+		rules, err := ParseRules("rules.yaml", []byte(content))
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+
+		assert.Equal(t, WindowScope, rules[0].Scope)
+		assert.Equal(t, 3, rules[0].Window)
+
+		all, ok := rules[1].Condition.(*All)
+		require.True(t, ok)
+		assert.Equal(t, BlockScope, all.Scope)
+	})
+
+	t.Run("Should parse the anchor of rules of the not type and of not conditions", func(t *testing.T) {
+		content := "- id: HS-1\n  name: Test\n  severity: LOW\n  type: not\n  anchor: <configuration>\n" +
+			"  expressions: [<secure>]\n" +
+			"- id: HS-2\n  name: Test\n  severity: LOW\n  condition:\n    not: {regex: <secure>}\n" +
+			"    anchor: <configuration>\n"
+
+		rules, err := ParseRules("rules.yaml", []byte(content))
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+
+		assert.Equal(t, "<configuration>", rules[0].Anchor.String())
+
+		not, ok := rules[1].Condition.(*Not)
+		require.True(t, ok)
+		assert.Equal(t, &Regex{Expression: regexp.MustCompile("<configuration>")}, not.Anchor)
+	})
+
+	t.Run("Should parse the groups of rules and of regex conditions", func(t *testing.T) {
+		content := "- id: HS-1\n  name: Key for {{.service}}\n  severity: LOW\n  group: 1\n" +
+			"  expressions: ['(?P<service>\\w+)_key']\n" +
+			"- id: HS-2\n  name: Test\n  severity: LOW\n  condition:\n    regex: 'key=(?P<key>\\w+)'\n    group: key\n"
+
+		rules, err := ParseRules("rules.yaml", []byte(content))
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+
+		assert.Equal(t, "1", rules[0].Group)
+		assert.Equal(t, &Regex{Expression: regexp.MustCompile(`key=(?P<key>\w+)`), Group: "key"}, rules[1].Condition)
+
+		findings, err := rules[0].RunFile(engine.NewFile("main.py", []byte("aws_key")))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "Key for aws", findings[0].Name)
+		assert.Equal(t, 4, findings[0].SourceLocation.EndColumn)
+	})
+
+	t.Run("Should return no rules for an empty file", func(t *testing.T) {
+		rules, err := ParseRules("rules.yaml", nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("Should run the parsed rules", func(t *testing.T) {
+		rules, err := ParseRules("rules.yaml", []byte(weakHashRuleYAML))
+		require.NoError(t, err)
+
+		content := []byte("import java.security.MessageDigest;\nMessageDigest.getInstance(\"MD5\");")
+
+		findings, err := rules[0].RunFile(engine.NewFile("Hash.java", content))
+		assert.NoError(t, err)
+		assert.Len(t, findings, 1)
+
+		findings, err = rules[0].RunFile(engine.NewFile("Hash.go", content))
+		assert.NoError(t, err)
+		assert.Empty(t, findings)
+	})
+}
+
+// nolint:funlen // table of invalid rules files
+func TestParseRulesErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		content  string
+		expected LoadError
+		cause    error
+	}{
+		{
+			name:     "Should return an error for invalid YAML",
+			content:  "rules: [",
+			expected: LoadError{File: "rules.yaml"},
+		},
+		{
+			name:     "Should return an error for malformed YAML that the parser can't handle",
+			content:  "0: [:!00 \xef",
+			expected: LoadError{File: "rules.yaml"},
+		},
+		{
+			name:     "Should return an error for a file that is not a list of rules",
+			content:  "rules: HS-JAVA-1",
+			expected: LoadError{File: "rules.yaml", Line: 1, Field: "rules"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for unknown fields of the file",
+			content:  "rule: []",
+			expected: LoadError{File: "rules.yaml", Line: 1, Field: "rule"},
+			cause:    ErrUnknownField,
+		},
+		{
+			name:     "Should return an error for unknown fields of the rules",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  expression: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 4, Field: "expression"},
+			cause:    ErrUnknownField,
+		},
+		{
+			name:     "Should return an error for required fields",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  expressions: []",
+			expected: LoadError{File: "rules.yaml", Line: 1, RuleID: "HS-1", Field: "expressions"},
+			cause:    ErrRequiredField,
+		},
+		{
+			name:     "Should return an error for fields with invalid types",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  expressions:\n    key: a",
+			expected: LoadError{File: "rules.yaml", Line: 5, Field: "expressions"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for invalid severities",
+			content:  "- id: HS-1\n  name: Test\n  severity: SEVERE\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 3, RuleID: "HS-1", Field: "severity"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for invalid confidences",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  confidence: SURE\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "confidence"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for invalid match types",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  type: xor\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "type"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for conditions with an unknown operator",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    all:\n      - xor: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 6, RuleID: "HS-1", Field: "condition.all[0].xor"},
+			cause:    ErrUnknownField,
+		},
+		{
+			name:     "Should return an error for conditions with more than one operator",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    regex: a\n    not: {regex: b}",
+			expected: LoadError{File: "rules.yaml", Line: 5, RuleID: "HS-1", Field: "condition"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for conditions with an empty list of conditions",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    not:\n      any: []",
+			expected: LoadError{File: "rules.yaml", Line: 6, RuleID: "HS-1", Field: "condition.not.any"},
+			cause:    ErrRequiredField,
+		},
+		{
+			name:     "Should return an error for conditions with an invalid regular expression",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    any:\n      - regex: a\n      - regex: (",
+			expected: LoadError{File: "rules.yaml", Line: 7, RuleID: "HS-1", Field: "condition.any[1].regex"},
+		},
+		{
+			name:     "Should return an error for rules with both expressions and condition",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  expressions: [a]\n  condition: {regex: b}",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "expressions"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for scopes of rules that are not of the and type",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  scope: line\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "scope"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for unknown scopes",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  type: and\n  scope: function\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 5, RuleID: "HS-1", Field: "scope"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for window scopes without window",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    all: [{regex: a}]\n    scope: window",
+			expected: LoadError{File: "rules.yaml", Line: 6, RuleID: "HS-1", Field: "condition.window"},
+			cause:    ErrRequiredField,
+		},
+		{
+			name:     "Should return an error for windows of other scopes",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  type: and\n  window: 2\n  expressions: [a]",
+			expected: LoadError{File: "rules.yaml", Line: 5, RuleID: "HS-1", Field: "window"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for scopes of operators other than all",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    any: [{regex: a}]\n    scope: line",
+			expected: LoadError{File: "rules.yaml", Line: 5, RuleID: "HS-1", Field: "condition"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for anchors of rules that are not of the not type",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  anchor: a\n  expressions: [b]",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "anchor"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for anchors of operators other than not",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    regex: a\n    anchor: b",
+			expected: LoadError{File: "rules.yaml", Line: 5, RuleID: "HS-1", Field: "condition"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for invalid anchors",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    not: {regex: a}\n    anchor: (",
+			expected: LoadError{File: "rules.yaml", Line: 6, RuleID: "HS-1", Field: "condition.anchor"},
+		},
+		{
+			name:     "Should return an error for groups that none of the expressions have",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  group: secret\n  expressions: [a, (b)]",
+			expected: LoadError{File: "rules.yaml", Line: 4, RuleID: "HS-1", Field: "group"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name:     "Should return an error for unknown groups of regex conditions",
+			content:  "- id: HS-1\n  name: Test\n  severity: LOW\n  condition:\n    regex: (a)\n    group: 2",
+			expected: LoadError{File: "rules.yaml", Line: 6, RuleID: "HS-1", Field: "condition.group"},
+			cause:    ErrInvalidValue,
+		},
+		{
+			name: "Should return an error for duplicated rule IDs in the same file",
+			content: "- {id: HS-1, name: A, severity: LOW, expressions: [a]}\n" +
+				"- {id: HS-1, name: B, severity: LOW, expressions: [b]}",
+			expected: LoadError{File: "rules.yaml", Line: 2, RuleID: "HS-1", Field: "id"},
+			cause:    ErrDuplicatedRuleID,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules("rules.yaml", []byte(tt.content))
+			assert.Nil(t, rules)
+
+			var loadErr *LoadError
+			require.True(t, errors.As(err, &loadErr), "unexpected error %v", err)
+
+			assert.Equal(t, tt.expected.File, loadErr.File)
+			assert.Equal(t, tt.expected.Line, loadErr.Line)
+			assert.Equal(t, tt.expected.RuleID, loadErr.RuleID)
+			assert.Equal(t, tt.expected.Field, loadErr.Field)
+
+			if tt.cause != nil {
+				assert.ErrorIs(t, err, tt.cause)
+			}
+		})
+	}
+}
+
+func TestParseRulesExpressionError(t *testing.T) {
+	t.Run("Should return the line of the invalid expression without the position of a parenthesis", func(t *testing.T) {
+		content := "- id: HS-1\n  name: Test\n  severity: LOW\n  expressions:\n    - valid\n    - 'password\\s*=\\s*(\"'"
+
+		_, err := ParseRules("rules.yaml", []byte(content))
+
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr))
+		assert.Equal(t, 6, loadErr.Line)
+		assert.Equal(t, "expressions[1]", loadErr.Field)
+
+		var expressionErr *ExpressionError
+		require.True(t, errors.As(err, &expressionErr))
+		assert.Equal(t, `password\s*=\s*("`, expressionErr.Expression)
+		assert.Equal(t, -1, expressionErr.Position)
+
+		assert.Contains(t, err.Error(), "rules.yaml:6: HS-1: expressions[1]: error parsing regexp")
+	})
+
+	testcases := []struct {
+		name       string
+		expression string
+		expected   int
+	}{
+		{name: "Should return the position of an invalid part in the middle", expression: `token=\x{zz}`, expected: 6},
+		{name: "Should return the position of an invalid part at the start", expression: `*token`, expected: 0},
+		{name: "Should return the position of the class without closing bracket", expression: `[a]x[a`, expected: 4},
+		{name: "Should return an unknown position for an unexpected parenthesis", expression: `foo)bar`, expected: -1},
+		{name: "Should return an unknown position for a missing parenthesis", expression: `foo(bar`, expected: -1},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			content := fmt.Sprintf("- id: HS-1\n  name: Test\n  severity: LOW\n  expressions: ['%s']", tt.expression)
+
+			_, err := ParseRules("rules.yaml", []byte(content))
+
+			var expressionErr *ExpressionError
+			require.True(t, errors.As(err, &expressionErr))
+			assert.Equal(t, tt.expected, expressionErr.Position)
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Run("Should load the rules files of the directories recursively and the files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRulesFile(t, dir, filepath.Join("java", "weak-hash.yaml"), weakHashRuleYAML)
+		writeRulesFile(t, dir, filepath.Join("leaks", "secret.json"), hardcodedSecretRuleJSON)
+		writeRulesFile(t, dir, "README.md", "# not a rules file")
+		file := writeRulesFile(t, t.TempDir(), "custom.rules", "- {id: HS-2, name: A, severity: LOW, expressions: [a]}")
+
+		rules, err := LoadRules(dir, file)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, rule := range rules {
+			ids = append(ids, rule.(engine.MetadataProvider).GetMetadata().ID)
+		}
+
+		assert.Equal(t, []string{"HS-JAVA-1", "HS-LEAKS-1", "HS-2"}, ids)
+	})
+
+	t.Run("Should return an error for duplicated rule IDs in different files", func(t *testing.T) {
+		dir := t.TempDir()
+		first := writeRulesFile(t, dir, "a.yaml", weakHashRuleYAML)
+		second := writeRulesFile(t, dir, "b.yaml", weakHashRuleYAML)
+
+		_, err := LoadRules(dir)
+
+		assert.ErrorIs(t, err, ErrDuplicatedRuleID)
+
+		var loadErr *LoadError
+		require.True(t, errors.As(err, &loadErr))
+		assert.Equal(t, second, loadErr.File)
+		assert.Contains(t, err.Error(), "already defined at "+first+":2")
+	})
+
+	t.Run("Should return an error when the path doesn't exist", func(t *testing.T) {
+		_, err := LoadRules(filepath.Join(t.TempDir(), "missing"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
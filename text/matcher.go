@@ -0,0 +1,197 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+// literalMatcher is an Aho-Corasick automaton that searches for many literals with a single scan of the content.
+// ASCII letters are compared ignoring case, so the matcher can report a literal that only appears with a different
+// case, but it never misses one that is present. To keep the transition table small, bytes are mapped into classes,
+// all bytes that don't appear in any literal share the same class
+type literalMatcher struct {
+	classes     [256]int // classes maps each byte into its class
+	classCount  int      // classCount holds the number of classes, including the class of the unknown bytes
+	transitions []int    // transitions holds the next state for each state and class (state*classCount + class)
+	outputs     [][]int  // outputs holds the literals indexes found when reaching each state
+	literals    int      // literals holds the number of literals searched by the matcher
+}
+
+// trieNode represents a node of the trie used to build the automaton
+type trieNode struct {
+	children map[int]int
+	fail     int
+	outputs  []int
+}
+
+// newLiteralMatcher builds the automaton for the literals
+func newLiteralMatcher(literals []string) *literalMatcher {
+	m := &literalMatcher{literals: len(literals)}
+
+	m.setClasses(literals)
+
+	trie := m.buildTrie(literals)
+	m.buildTransitions(trie)
+
+	return m
+}
+
+// setClasses assigns a class for each byte used by the literals, upper and lower case ASCII letters share the class
+func (m *literalMatcher) setClasses(literals []string) {
+	m.classCount = 1
+
+	for _, literal := range literals {
+		for index := 0; index < len(literal); index++ {
+			b := toLowerASCII(literal[index])
+			if m.classes[b] != 0 {
+				continue
+			}
+
+			m.classes[b] = m.classCount
+			m.classes[toUpperASCII(b)] = m.classCount
+			m.classCount++
+		}
+	}
+}
+
+// buildTrie creates the trie of the literals, the root is the node 0
+func (m *literalMatcher) buildTrie(literals []string) []*trieNode {
+	trie := []*trieNode{{children: map[int]int{}}}
+
+	for literalIndex, literal := range literals {
+		node := 0
+
+		for index := 0; index < len(literal); index++ {
+			trie, node = childOf(trie, node, m.classes[literal[index]])
+		}
+
+		trie[node].outputs = append(trie[node].outputs, literalIndex)
+	}
+
+	return trie
+}
+
+// childOf returns the child of the node for the class, adding it to the trie when it doesn't exist yet
+func childOf(trie []*trieNode, node, class int) ([]*trieNode, int) {
+	if child, ok := trie[node].children[class]; ok {
+		return trie, child
+	}
+
+	child := len(trie)
+	trie[node].children[class] = child
+
+	return append(trie, &trieNode{children: map[int]int{}}), child
+}
+
+// buildTransitions walks the trie in breadth-first order computing the fail links of each node and filling the
+// complete transition table, so the scan never needs to follow fail links
+func (m *literalMatcher) buildTransitions(trie []*trieNode) {
+	m.transitions = make([]int, len(trie)*m.classCount)
+	m.outputs = make([][]int, len(trie))
+
+	queue := []int{0}
+
+	for len(queue) > 0 {
+		queue = m.visitNode(trie, queue[0], queue[1:])
+	}
+}
+
+// visitNode sets the outputs of the node, including the ones of its fail link, and its transitions, returning the
+// queue with the children of the node
+func (m *literalMatcher) visitNode(trie []*trieNode, node int, queue []int) []int {
+	m.outputs[node] = append(trie[node].outputs, trie[trie[node].fail].outputs...)
+	trie[node].outputs = m.outputs[node]
+
+	for class := 0; class < m.classCount; class++ {
+		queue = m.setTransition(trie, node, class, queue)
+	}
+
+	return queue
+}
+
+// setTransition sets the transition of the node for the class. When the node has a child for the class, the child
+// fail link is also set and the child is queued to be visited
+func (m *literalMatcher) setTransition(trie []*trieNode, node, class int, queue []int) []int {
+	child, ok := trie[node].children[class]
+	if !ok {
+		m.transitions[m.index(node, class)] = m.failTransition(trie, node, class)
+
+		return queue
+	}
+
+	trie[child].fail = m.failTransition(trie, node, class)
+	m.transitions[m.index(node, class)] = child
+
+	return append(queue, child)
+}
+
+// failTransition returns the state reached with the class from the fail link of the node. The fail link of the root
+// and of its children is the root itself
+func (m *literalMatcher) failTransition(trie []*trieNode, node, class int) int {
+	if node == 0 {
+		return 0
+	}
+
+	return m.transitions[m.index(trie[node].fail, class)]
+}
+
+// index returns the index of the transition of the state for the class in the transition table
+func (m *literalMatcher) index(state, class int) int {
+	return state*m.classCount + class
+}
+
+// scan searches the content for all literals and returns which of them were found. The scan stops as soon as all
+// literals are found
+func (m *literalMatcher) scan(content []byte) []bool {
+	found := make([]bool, m.literals)
+	remaining := m.literals
+	state := 0
+
+	for index := 0; index < len(content) && remaining > 0; index++ {
+		state = m.transitions[m.index(state, m.classes[content[index]])]
+		remaining -= markFound(found, m.outputs[state])
+	}
+
+	return found
+}
+
+// markFound marks the literals as found, returning how many of them were not found before
+func markFound(found []bool, literals []int) int {
+	count := 0
+
+	for _, literal := range literals {
+		if !found[literal] {
+			found[literal] = true
+			count++
+		}
+	}
+
+	return count
+}
+
+// toLowerASCII converts an ASCII upper case letter into lower case, any other byte is returned as it is
+func toLowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + ('a' - 'A')
+	}
+
+	return b
+}
+
+// toUpperASCII converts an ASCII lower case letter into upper case, any other byte is returned as it is
+func toUpperASCII(b byte) byte {
+	if b >= 'a' && b <= 'z' {
+		return b - ('a' - 'A')
+	}
+
+	return b
+}
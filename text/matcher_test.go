@@ -0,0 +1,74 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteralMatcherScan(t *testing.T) {
+	testCases := []struct {
+		name          string
+		literals      []string
+		content       string
+		expectedFound []bool
+	}{
+		{
+			name:          "Should find all literals present in the content",
+			literals:      []string{"he", "she", "his", "hers"},
+			content:       "ushers",
+			expectedFound: []bool{true, true, false, true},
+		},
+		{
+			name:          "Should find literals ignoring the case of ASCII letters",
+			literals:      []string{"Logger.Fatal", "echoInstance.Start"},
+			content:       "LOGGER.FATAL(ECHOINSTANCE.START())",
+			expectedFound: []bool{true, true},
+		},
+		{
+			name:          "Should find overlapping literals and literals that are suffixes of others",
+			literals:      []string{"abcd", "bc", "c", "cde"},
+			content:       "xabcdex",
+			expectedFound: []bool{true, true, true, true},
+		},
+		{
+			name:          "Should find literals after failed partial matches",
+			literals:      []string{"aab", "ab"},
+			content:       "aaab",
+			expectedFound: []bool{true, true},
+		},
+		{
+			name:          "Should not find literals that are not present in the content",
+			literals:      []string{"password", "secret"},
+			content:       "passwor secre",
+			expectedFound: []bool{false, false},
+		},
+		{
+			name:          "Should find non ASCII literals",
+			literals:      []string{"senha", "contraseña"},
+			content:       "contraseña = 1234",
+			expectedFound: []bool{false, true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			matcher := newLiteralMatcher(testCase.literals)
+			assert.Equal(t, testCase.expectedFound, matcher.scan([]byte(testCase.content)))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"strings"
+	"text/template"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+// messages holds the templates of the name and description of a rule, which interpolate the named capture groups
+// of the matches, like "Hard-coded key for {{.service}}". Each template is nil when its text has no action or isn't a
+// valid template, in which case the text is reported as is
+type messages struct {
+	name        *template.Template
+	description *template.Template
+}
+
+// newMessages parses the name and description templates
+func newMessages(name, description string) *messages {
+	return &messages{name: parseMessageTemplate(name), description: parseMessageTemplate(description)}
+}
+
+// parseMessageTemplate parses the text as a template, returning nil when it has no action or isn't a valid template,
+// like "Avoid {{ user input }} in Angular templates", so the text of a rule that doesn't interpolate groups can have
+// braces. Groups that are not in the match are interpolated as empty strings
+func parseMessageTemplate(text string) *template.Template {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil
+	}
+
+	return tmpl
+}
+
+// isEmpty checks if there is no template to interpolate
+func (m *messages) isEmpty() bool {
+	return m.name == nil && m.description == nil
+}
+
+// interpolate executes the templates of the finding name and description with the named groups of its match
+func (m *messages) interpolate(finding *engine.Finding, groups map[string]string) {
+	finding.Name = executeMessageTemplate(m.name, finding.Name, groups)
+	finding.Description = executeMessageTemplate(m.description, finding.Description, groups)
+}
+
+// executeMessageTemplate executes the template with the groups, returning the text as is when there is no template
+// or its execution fails
+func executeMessageTemplate(tmpl *template.Template, text string, groups map[string]string) string {
+	if tmpl == nil {
+		return text
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, groups); err != nil {
+		return text
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+// nolint:funlen // table of templates
+func TestRunFileWithMessageTemplates(t *testing.T) {
+	content := "stripe_key = \"sk_live_123\"\ntwilio_key = \"tw_456\"\nother = 1\n"
+
+	testcases := []struct {
+		name                 string
+		rule                 *Rule
+		expectedNames        []string
+		expectedDescriptions []string
+	}{
+		{
+			name: "Should interpolate the named groups of each match into the name and description",
+			rule: &Rule{
+				Metadata: engine.Metadata{
+					Name:        "Hard-coded key for {{.service}}",
+					Description: "The {{.service}} key {{.secret}} is hard-coded",
+				},
+				Expressions: []*regexp.Regexp{regexp.MustCompile(`(?P<service>\w+)_key = "(?P<secret>[^"]+)"`)},
+			},
+			expectedNames:        []string{"Hard-coded key for stripe", "Hard-coded key for twilio"},
+			expectedDescriptions: []string{"The stripe key sk_live_123 is hard-coded", "The twilio key tw_456 is hard-coded"},
+		},
+		{
+			name: "Should interpolate the groups that are not in the match as empty",
+			rule: &Rule{
+				Metadata:    engine.Metadata{Name: "Key{{.missing}}", Description: "Key"},
+				Expressions: []*regexp.Regexp{regexp.MustCompile(`stripe_key`)},
+			},
+			expectedNames:        []string{"Key"},
+			expectedDescriptions: []string{"Key"},
+		},
+		{
+			name: "Should interpolate the groups of file level findings as empty",
+			rule: &Rule{
+				Metadata:    engine.Metadata{Name: "Missing {{.service}}", Description: "Missing"},
+				Type:        NotMatch,
+				Expressions: []*regexp.Regexp{regexp.MustCompile(`(?P<service>aws)_key`)},
+			},
+			expectedNames:        []string{"Missing "},
+			expectedDescriptions: []string{"Missing"},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := tt.rule.RunFile(engine.NewFile("settings.py", []byte(content)))
+			require.NoError(t, err)
+			require.Len(t, findings, len(tt.expectedNames))
+
+			for index := range findings {
+				assert.Equal(t, tt.expectedNames[index], findings[index].Name)
+				assert.Equal(t, tt.expectedDescriptions[index], findings[index].Description)
+			}
+		})
+	}
+
+	t.Run("Should report the texts that aren't valid templates as is", func(t *testing.T) {
+		rule := &Rule{
+			Metadata: engine.Metadata{
+				Name:        "Key {{.service",
+				Description: "Avoid {{ user input }} in Angular templates",
+			},
+			Expressions: []*regexp.Regexp{regexp.MustCompile(`stripe_key`)},
+		}
+
+		findings, err := rule.RunFile(engine.NewFile("settings.py", []byte(content)))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+
+		assert.Equal(t, "Key {{.service", findings[0].Name)
+		assert.Equal(t, "Avoid {{ user input }} in Angular templates", findings[0].Description)
+	})
+
+	t.Run("Should report the text as is when the template fails", func(t *testing.T) {
+		rule := &Rule{
+			Metadata:    engine.Metadata{Name: "Key {{index . 1}}"},
+			Expressions: []*regexp.Regexp{regexp.MustCompile(`(?P<service>stripe)_key`)},
+		}
+
+		findings, err := rule.RunFile(engine.NewFile("settings.py", []byte(content)))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+
+		assert.Equal(t, "Key {{index . 1}}", findings[0].Name)
+	})
+}
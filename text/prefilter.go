@@ -0,0 +1,299 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+// maxAlternateLiterals is the maximum number of literals that an expression can require, expressions with more
+// alternatives than it are always evaluated, since the scan would find some of them in almost any file
+const maxAlternateLiterals = 32
+
+// prefilter holds the literals required by each one of the expressions of a rule. The literals of all rules are
+// searched by a single matcher, with one scan of each file shared by all rules, and an expression needs to be
+// evaluated only if at least one of its required literals is present in the file. Expressions without required
+// literals, or with a literal prefix, which the regexp package already searches for quickly, are always evaluated
+type prefilter struct {
+	required    [][]int // required holds the indexes of the literals required by each expression, nil if there is none
+	hasLiterals bool    // hasLiterals holds if any expression requires literals, otherwise the file is never scanned
+}
+
+// newPrefilter extracts the required literals of each expression and adds them to the literals of all rules
+func newPrefilter(expressions []*regexp.Regexp) *prefilter {
+	p := &prefilter{required: make([][]int, len(expressions))}
+
+	for index, expression := range expressions {
+		if prefix, _ := expression.LiteralPrefix(); prefix != "" {
+			continue
+		}
+
+		if literals := requiredLiterals(expression); literals != nil {
+			p.required[index], p.hasLiterals = allLiterals.add(literals), true
+		}
+	}
+
+	return p
+}
+
+// candidates returns which expressions can match the file. An expression that is not a candidate is guaranteed to
+// not match the file, so it doesn't need to be evaluated
+func (p *prefilter) candidates(file *engine.File) []bool {
+	candidates := make([]bool, len(p.required))
+	scan := p.scan(file)
+
+	for index, required := range p.required {
+		candidates[index] = required == nil || scan.isAnyFound(required)
+	}
+
+	return candidates
+}
+
+// scan returns the literals found in the file, which is only scanned when some expression requires literals
+func (p *prefilter) scan(file *engine.File) *literalScan {
+	if !p.hasLiterals {
+		return nil
+	}
+
+	return getLiteralScan(file)
+}
+
+// literalSet holds the literals required by the expressions of all rules, each literal has a single index even if
+// it's required by many rules. The matcher is built again when literals are added, as rules are compiled on their
+// first run
+type literalSet struct {
+	mutex    sync.Mutex
+	indexes  map[string]int
+	literals []string
+	matcher  *literalMatcher
+}
+
+// allLiterals is the set of the literals required by the expressions of all rules
+var allLiterals = &literalSet{indexes: map[string]int{}}
+
+// add adds the literals to the set, returning their indexes
+func (s *literalSet) add(literals []string) []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	indexes := make([]int, 0, len(literals))
+
+	for _, literal := range literals {
+		if _, ok := s.indexes[literal]; !ok {
+			s.indexes[literal] = len(s.literals)
+			s.literals = append(s.literals, literal)
+		}
+
+		indexes = append(indexes, s.indexes[literal])
+	}
+
+	return indexes
+}
+
+// getMatcher returns the matcher of all literals of the set, building it again if literals were added since the
+// last time it was built
+func (s *literalSet) getMatcher() *literalMatcher {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.matcher == nil || s.matcher.literals < len(s.literals) {
+		s.matcher = newLiteralMatcher(s.literals)
+	}
+
+	return s.matcher
+}
+
+// literalScan holds which literals of the set were found in a file. Literals added to the set after the scan were
+// not searched, so they are considered found
+type literalScan struct {
+	found []bool
+}
+
+// literalScanKey is the key used to share the literal scan between all text rules analyzing the same file
+type literalScanKey struct{}
+
+// getLiteralScan returns the literals found in the file, the file is scanned only by the first rule that needs it
+func getLiteralScan(file *engine.File) *literalScan {
+	value, _ := file.Value(literalScanKey{}, func() (interface{}, error) {
+		return &literalScan{found: allLiterals.getMatcher().scan(file.Content)}, nil
+	})
+
+	return value.(*literalScan)
+}
+
+// isAnyFound checks if any of the literals was found in the file
+func (s *literalScan) isAnyFound(literals []int) bool {
+	for _, literal := range literals {
+		if literal >= len(s.found) || s.found[literal] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requiredLiterals returns a set of literals in which at least one must be present in any text matched by the
+// expression. If it's not possible to determine this set, nil will be returned
+func requiredLiterals(expression *regexp.Regexp) []string {
+	parsed, err := syntax.Parse(expression.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	literals, ok := literalsOf(parsed.Simplify())
+	if !ok {
+		return nil
+	}
+
+	return literals
+}
+
+// literalsOf walks the regular expression syntax tree looking for the literals required by it
+// nolint:exhaustive // all others operations don't require any literal
+func literalsOf(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return literalOf(re)
+	case syntax.OpCapture, syntax.OpPlus, syntax.OpRepeat:
+		return subLiteralsOf(re)
+	case syntax.OpConcat:
+		return bestLiteralsOf(re.Sub)
+	case syntax.OpAlternate:
+		return alternateLiteralsOf(re.Sub)
+	}
+
+	return nil, false
+}
+
+// literalOf returns the literal of the expression. The matcher compares ASCII letters ignoring case, so case-folded
+// literals are accepted as long as all its runes only fold into ASCII runes. Otherwise, the longest part of the
+// literal without these runes is used (e.g. (?i)secret can also match "ſecret", so "ecret" is used). Literals with
+// the replacement character are ignored, since it also matches invalid UTF-8 bytes
+func literalOf(re *syntax.Regexp) ([]string, bool) {
+	if hasRuneError(re.Rune) {
+		return nil, false
+	}
+
+	if re.Flags&syntax.FoldCase == 0 {
+		return []string{string(re.Rune)}, len(re.Rune) > 0
+	}
+
+	longest := longestASCIIFold(re.Rune)
+
+	return []string{longest}, longest != ""
+}
+
+// subLiteralsOf returns the literals of the sub expression of a capture or a repetition. Repetitions that can match
+// zero times don't require any literal
+func subLiteralsOf(re *syntax.Regexp) ([]string, bool) {
+	if re.Op == syntax.OpRepeat && re.Min < 1 {
+		return nil, false
+	}
+
+	return literalsOf(re.Sub[0])
+}
+
+// hasRuneError checks if any of the runes is the replacement character
+func hasRuneError(runes []rune) bool {
+	for _, r := range runes {
+		if r == utf8.RuneError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// longestASCIIFold returns the longest sequence of the runes that only fold into ASCII runes
+func longestASCIIFold(runes []rune) string {
+	longest, start := "", 0
+
+	for index, r := range runes {
+		if !isASCIIFold(r) {
+			start = index + 1
+		} else if index+1-start > len(longest) {
+			longest = string(runes[start : index+1])
+		}
+	}
+
+	return longest
+}
+
+// isASCIIFold checks if the rune and all runes that it's equivalent under case folding are ASCII
+func isASCIIFold(r rune) bool {
+	for folded := unicode.SimpleFold(r); ; folded = unicode.SimpleFold(folded) {
+		if folded >= utf8.RuneSelf {
+			return false
+		}
+
+		if folded == r {
+			return r < utf8.RuneSelf
+		}
+	}
+}
+
+// bestLiteralsOf chooses between the literals required by each part of a concatenation the most selective ones,
+// which are the ones whose shortest literal is the longest
+func bestLiteralsOf(subs []*syntax.Regexp) (best []string, found bool) {
+	bestScore := 0
+
+	for _, sub := range subs {
+		literals, ok := literalsOf(sub)
+		if !ok {
+			continue
+		}
+
+		if score := shortestLength(literals); score > bestScore {
+			best, bestScore, found = literals, score, true
+		}
+	}
+
+	return best, found
+}
+
+// alternateLiteralsOf returns the union of the literals of each alternative, all of them need to require literals
+func alternateLiteralsOf(subs []*syntax.Regexp) ([]string, bool) {
+	var union []string
+
+	for _, sub := range subs {
+		literals, ok := literalsOf(sub)
+		if !ok {
+			return nil, false
+		}
+
+		union = append(union, literals...)
+	}
+
+	return union, len(union) <= maxAlternateLiterals
+}
+
+// shortestLength returns the length of the shortest literal
+func shortestLength(literals []string) int {
+	shortest := len(literals[0])
+
+	for _, literal := range literals[1:] {
+		if len(literal) < shortest {
+			shortest = len(literal)
+		}
+	}
+
+	return shortest
+}
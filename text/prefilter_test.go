@@ -0,0 +1,202 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	testCases := []struct {
+		name             string
+		expression       string
+		expectedLiterals []string
+	}{
+		{
+			name:             "Should return the literal of a literal expression",
+			expression:       `MessageDigest\.getInstance`,
+			expectedLiterals: []string{"MessageDigest.getInstance"},
+		},
+		{
+			name:             "Should return the longest literal of a concatenation",
+			expression:       `Logger\.Fatal\(.*\)\s+echoInstance`,
+			expectedLiterals: []string{"Logger.Fatal("},
+		},
+		{
+			name:             "Should return all literals of an alternation",
+			expression:       `(md5|sha1)\.New\(`,
+			expectedLiterals: []string{".New("},
+		},
+		{
+			name:             "Should return the union of the alternatives when they are the best option",
+			expression:       `(?:md5\.New|sha1\.Sum)`,
+			expectedLiterals: []string{"md5.New", "sha1.Sum"},
+		},
+		{
+			name:             "Should return the literal of a required repetition",
+			expression:       `(password)+\s*=`,
+			expectedLiterals: []string{"password"},
+		},
+		{
+			name:             "Should ignore the case folded runes that are equivalent to non ASCII runes",
+			expression:       `(?i)secret`,
+			expectedLiterals: []string{"ECRET"},
+		},
+		{
+			name:             "Should return nil when the literal is optional",
+			expression:       `(password)?\s*\d`,
+			expectedLiterals: nil,
+		},
+		{
+			name:             "Should return nil when an alternative doesn't have a literal",
+			expression:       `password|\d+`,
+			expectedLiterals: nil,
+		},
+		{
+			name:             "Should return nil when there is no literal",
+			expression:       `[a-z]+\s*\d`,
+			expectedLiterals: nil,
+		},
+		{
+			name:             "Should return nil when the literal contains the replacement character",
+			expression:       "�key",
+			expectedLiterals: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			literals := requiredLiterals(regexp.MustCompile(testCase.expression))
+			assert.ElementsMatch(t, testCase.expectedLiterals, literals)
+		})
+	}
+}
+
+func TestPrefilterCandidates(t *testing.T) {
+	expressions := []*regexp.Regexp{
+		regexp.MustCompile(`Logger\.Fatal`),
+		regexp.MustCompile(`(?i)select .* from`),
+		regexp.MustCompile(`(md5|sha1)\.New\(`),
+		regexp.MustCompile(`\d{3}-\d{4}`),
+		regexp.MustCompile(`(?i)ſecret`),
+		regexp.MustCompile(`(should|must)-not-match`),
+	}
+
+	contents := []string{
+		"",
+		"Logger.Fatal(err)",
+		"logger.fatal(err)",
+		"SELECT * FROM users",
+		"h := sha1.New()",
+		"call 555-1234",
+		"SECRET = 'abc'",
+		"sha256.New()",
+	}
+
+	p := newPrefilter(expressions)
+
+	for _, content := range contents {
+		candidates := p.candidates(engine.NewFile("main.go", []byte(content)))
+
+		for index, expression := range expressions {
+			if expression.MatchString(content) {
+				assert.Truef(t, candidates[index], "expression %s should be a candidate for %q", expression, content)
+			}
+		}
+
+		assert.Falsef(t, candidates[5], "expression %s should not be a candidate for %q", expressions[5], content)
+	}
+}
+
+func TestNewPrefilter(t *testing.T) {
+	t.Run("Should not require literals of the expressions with a literal prefix", func(t *testing.T) {
+		p := newPrefilter([]*regexp.Regexp{regexp.MustCompile(`Logger\.Fatal`), regexp.MustCompile(`(?i)logger`)})
+
+		assert.Nil(t, p.required[0])
+		assert.NotNil(t, p.required[1])
+	})
+
+	t.Run("Should share the index of the literals required by many rules", func(t *testing.T) {
+		first := newPrefilter([]*regexp.Regexp{regexp.MustCompile(`\bshared-literal`)})
+		second := newPrefilter([]*regexp.Regexp{regexp.MustCompile(`\s+shared-literal`)})
+
+		assert.Equal(t, first.required, second.required)
+	})
+}
+
+// benchmarkExpressions are expressions like the ones of the rules, most of them with a literal prefix
+var benchmarkExpressions = []string{
+	`MessageDigest\.getInstance\(["']MD5["']\)`,
+	`new\s+Random\(\)`,
+	`(?i)password\s*=\s*["'].+["']`,
+	`Runtime\.getRuntime\(\)\.exec\(`,
+	`\.setAllowFileAccess\(true\)`,
+	`(md5|sha1)\.New\(`,
+}
+
+// benchmarkContent returns a file of about 340 KB without matches of the benchmark expressions
+func benchmarkContent() []byte {
+	return []byte(strings.Repeat("public static void main(String[] args) { System.out.println(args); }\n", 5000))
+}
+
+func BenchmarkRunFileWithPrefilter(b *testing.B) {
+	var programs []*program
+
+	for _, expression := range benchmarkExpressions {
+		compiled, err := compileCondition(regex(expression))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		programs = append(programs, compiled)
+	}
+
+	content := benchmarkContent()
+	textFile := &File{Content: content}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		file := engine.NewFile("Main.java", content)
+
+		for _, compiled := range programs {
+			compiled.findMatches(textFile, compiled.prefilter.candidates(file))
+		}
+	}
+}
+
+func BenchmarkRunFileWithoutPrefilter(b *testing.B) {
+	var expressions []*regexp.Regexp
+
+	for _, expression := range benchmarkExpressions {
+		expressions = append(expressions, regexp.MustCompile(expression))
+	}
+
+	content := benchmarkContent()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, expression := range expressions {
+			expression.FindAllIndex(content, -1)
+		}
+	}
+}
@@ -15,11 +15,12 @@
 package text
 
 import (
-	"bytes"
-	"fmt"
-	"io"
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sync/atomic"
 
 	engine "github.com/ZupIT/horusec-engine"
 )
@@ -34,7 +35,8 @@ const (
 	// Regular do the exact same thing as OrMatch, will be depreciated in the future to simplify engine use
 	Regular
 
-	// NotMatch will report any file that don't match the regex expressions
+	// NotMatch will report any file that don't match the regex expressions, at the first match of the rule anchor or as
+	// a file level finding
 	NotMatch
 
 	// AndMatch need that all regex expressions match to report the vulnerability, it will get the first regex expression
@@ -42,196 +44,193 @@ const (
 	AndMatch
 )
 
-// peMagicBytes hexadecimal used to find windows binaries
-// elfMagicNumber hexadecimal used to find linux binaries
-var (
-	peMagicBytes   = []byte{'\x4D', '\x5A'}                 // MZ
-	elfMagicNumber = []byte{'\x7F', '\x45', '\x4C', '\x46'} // .ELF
-)
-
 // Rule represents the vulnerability that should be searched in the file. It contains some predefined information about
 // the vulnerability like the id, name, description, severity, confidence, match type that should be applied and the
 // regular expressions used to match the vulnerable code
 type Rule struct {
+	// Metadata holds the information of the vulnerability. The name and description can interpolate the named groups
+	// of the matches with text/template, like "Hard-coded key for {{.service}}", texts that aren't valid templates are
+	// reported as is
 	engine.Metadata
-	Type        MatchType
-	Expressions []*regexp.Regexp
+	Type        MatchType        // Type holds how the expressions are combined into the condition of the rule
+	Expressions []*regexp.Regexp // Expressions holds the regular expressions that match the vulnerable code
+	// Extensions restricts the files analyzed by the rule to the ones with these extensions, like .java, all files are
+	// analyzed when it's empty
+	Extensions []string
+	// Condition is an expression tree combining regular expressions, like "A and not B". It can't be used together
+	// with the type, expressions, scope, window, anchor and group, since they are mapped into a condition too
+	Condition Condition
+	Scope     ScopeType // Scope restricts where the expressions of AndMatch rules must match around the first one
+	Window    int       // Window holds the number of lines around each match of the WindowScope
+	// Anchor sets the location and code sample of the findings of NotMatch rules, which are file level findings
+	// without it
+	Anchor *regexp.Regexp
+	Group  string // Group holds the name or number of the capture group of the expressions reported as the finding
+
+	program atomic.Value // program holds the *compiledProgram of the rule, compiled on the first run of the rule
+}
+
+// compiledProgram is the result of the compilation of the rule condition and of its name and description templates
+type compiledProgram struct {
+	program  *program
+	messages *messages
+	err      error
 }
 
-// Run start a static code analysis using regular expressions, it will read the file content as bytes and create a text
-// file with it. The text file contains all information needed to find the vulnerable code when the regular expressions
-// match. There's also a validation to ignore binary files
+// Run start a static code analysis using regular expressions, it will read the file content as bytes and run the
+// analysis with it through RunFile
 func (r *Rule) Run(path string) ([]engine.Finding, error) {
-	content, err := r.getFileContent(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if r.isBinary(content) {
-		return nil, nil
-	}
+	return r.RunFile(engine.NewFile(path, content))
+}
 
-	textFile, err := NewTextFile(path, content)
+// RunFS works like Run, but reads the file with the name from the file system, like an embed.FS or a fstest.MapFS
+func (r *Rule) RunFS(fsys fs.FS, name string) ([]engine.Finding, error) {
+	content, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.runByRuleType(textFile)
+	return r.RunFile(engine.NewFile(name, content))
 }
 
-// getFileContent opens the file using the file path, reads and returns its contents as bytes. After all done closes
-// the file
-func (r *Rule) getFileContent(path string) ([]byte, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// RunFile start a static code analysis using regular expressions over a file already loaded by the engine. The text
+// file created from it contains all information needed to find the vulnerable code when the regular expressions
+// match, and it's shared with all others text rules analyzing the same file. There's also a validation to ignore
+// binary files and files without the rule extensions. Findings with an inline annotation, like "// horusec-ignore",
+// on the same line or on the comment line above are marked as suppressed
+func (r *Rule) RunFile(file *engine.File) ([]engine.Finding, error) {
+	if !r.acceptsExtension(file.Path) || file.IsBinary() {
+		return nil, nil
 	}
 
-	defer file.Close()
-
-	content, err := io.ReadAll(file)
+	textFile, err := getTextFile(file)
 	if err != nil {
 		return nil, err
 	}
 
-	return content, nil
-}
-
-// runByRuleType determines which match type should be applied and ran according the rule
-func (r *Rule) runByRuleType(file *File) ([]engine.Finding, error) {
-	switch r.Type {
-	// TODO: regular type do the exact same thing as OrMatch, will be depreciated in the future to simplify engine use
-	case OrMatch, Regular:
-		return r.runOrMatch(file)
-	case NotMatch:
-		return r.runNotMatch(file)
-	case AndMatch:
-		return r.runAndMatch(file)
+	findings, err := r.runCondition(file, textFile)
+	if err == nil {
+		suppressFindings(textFile, findings)
 	}
 
-	return nil, fmt.Errorf("invalid rule type")
+	return findings, err
 }
 
-// runNotMatch for each regex expression will search for matches in the file and return they index and create the
-// findings with them. Different of the other types, this type will report files that didn't have any match with each
-// one of the regex expressions.
-// TODO: since this match type search for files that didn't match the rules, we can't get a sample code,
-// line and column, witch lead to a really vague report. Need to be revisited and improved in the future.
-func (r *Rule) runNotMatch(file *File) ([]engine.Finding, error) {
-	var findings []engine.Finding
+// acceptsExtension checks if the rule should analyze the file according to the rule extensions, which are case
+// sensitive, like the extensions of the engine
+func (r *Rule) acceptsExtension(path string) bool {
+	if len(r.Extensions) == 0 {
+		return true
+	}
 
-	for _, expression := range r.Expressions {
-		if expression.FindAllIndex(file.Content, -1) == nil {
-			findings = append(findings, r.newFinding(file.RelativePath, "", 0, 0))
+	for _, extension := range r.Extensions {
+		if extension == engine.AcceptAnyExtension || extension == filepath.Ext(path) {
+			return true
 		}
 	}
 
-	return findings, nil
+	return false
 }
 
-// runAndMatch for each regex expression will search for matches in the file and return they index and create the
-// findings with them. If any of the regex expressions don't match, it should return nil, all regex expressions should
-// match to be a valid vulnerability. In case of all have matched the first finding will be returned to be used to
-// generate the report
-//nolint:funlen // necessary length, it's not a complex func, maybe can be improved in the future
-func (r *Rule) runAndMatch(file *File) ([]engine.Finding, error) {
-	var findings []engine.Finding
+// getProgram returns the compiled expression tree and templates of the rule, compiling them if it's the first time
+// the rule runs. The condition, type, expressions, name and description should not be changed after the first run,
+// since they will not be compiled again
+func (r *Rule) getProgram() *compiledProgram {
+	if compiled, ok := r.program.Load().(*compiledProgram); ok {
+		return compiled
+	}
 
-	isFailedToMatchAll := false
+	compiled := r.compile()
+	r.program.Store(compiled)
 
-	for _, expression := range r.Expressions {
-		findingIndexes := expression.FindAllIndex(file.Content, -1)
-		if findingIndexes != nil {
-			findings = append(findings, r.createFindingsFromIndexes(findingIndexes, file)...)
+	return compiled
+}
 
-			continue
-		}
+// compile compiles the expression tree and the name and description templates of the rule
+func (r *Rule) compile() *compiledProgram {
+	condition, err := r.getCondition()
+	if err != nil {
+		return &compiledProgram{err: err}
+	}
+
+	program, err := compileCondition(condition)
+
+	return &compiledProgram{program: program, messages: newMessages(r.Name, r.Description), err: err}
+}
 
-		isFailedToMatchAll = true
+// getCondition returns the condition of the rule, or maps its match type and expressions into one when it's not set
+func (r *Rule) getCondition() (Condition, error) {
+	if r.Condition == nil {
+		return conditionOf(r.Type, r.Expressions, r.Group, r.Anchor, r.Scope, r.Window)
+	}
 
-		break
+	if r.Type != OrMatch || len(r.Expressions) > 0 || r.hasMatchTypeOptions() {
+		return nil, errors.New("condition can't be used with the type, expressions, scope, window, anchor or group")
 	}
 
-	return r.getFirstFindingIfAllMatched(isFailedToMatchAll, findings), nil
+	return r.Condition, nil
 }
 
-// getFirstFindingIfAllMatched checks if all regex expressions matched, if not will return nil. In case of all of them
-// have match will get the first finding of the slice and return it to be used to generate the report
-func (r *Rule) getFirstFindingIfAllMatched(isFailedToMatchAll bool, findings []engine.Finding) []engine.Finding {
-	if isFailedToMatchAll {
-		return nil
-	}
+// hasMatchTypeOptions checks if any of the fields that modify the condition of the match type is set
+func (r *Rule) hasMatchTypeOptions() bool {
+	return r.Scope != FileScope || r.Window != 0 || r.Anchor != nil || r.Group != ""
+}
 
-	if len(findings) >= 1 {
-		return []engine.Finding{findings[0]}
+// runCondition evaluates the expression tree of the rule in the text file of the engine file, compiling it on the
+// first run of the rule
+func (r *Rule) runCondition(file *engine.File, textFile *File) ([]engine.Finding, error) {
+	compiled := r.getProgram()
+	if compiled.err != nil {
+		return nil, compiled.err
 	}
 
-	return nil
+	matches := compiled.program.findMatches(textFile, compiled.program.prefilter.candidates(file))
+
+	return r.newFindings(textFile, compiled, matches), nil
 }
 
-// runOrMatch for each regex expression will search for matches in the file and return they index and create the
-// findings with them. Since the OrMatch type rules can match many times, they can return more than one finding for rule
-func (r *Rule) runOrMatch(file *File) ([]engine.Finding, error) {
+// newFindings creates a finding for each one of the matches of the compiled expression tree in the file, with the
+// named groups of the match interpolated into its name and description
+func (r *Rule) newFindings(file *File, compiled *compiledProgram, matches []match) []engine.Finding {
 	var findings []engine.Finding
 
-	for _, expression := range r.Expressions {
-		findingIndexes := expression.FindAllIndex(file.Content, -1)
-		if findingIndexes != nil {
-			findings = append(findings, r.createFindingsFromIndexes(findingIndexes, file)...)
-
-			continue
+	for _, m := range matches {
+		finding := r.newMatchFinding(file, m)
+		if !compiled.messages.isEmpty() {
+			compiled.messages.interpolate(&finding, compiled.program.namedGroups(file, m))
 		}
+
+		findings = append(findings, finding)
 	}
 
-	return findings, nil
+	return findings
 }
 
-// createFindingsFromIndexes for each index found of a possible vulnerability will get the line, column and code sample
-// and create a new finding to append into the result
-func (r *Rule) createFindingsFromIndexes(findingIndexes [][]int, file *File) (findings []engine.Finding) {
-	for _, findingIndex := range findingIndexes {
-		line, column := file.FindLineAndColumn(findingIndex[0])
-		codeSample := file.ExtractSample(findingIndex[0])
-
-		findings = append(findings, r.newFinding(
-			file.RelativePath,
-			codeSample,
-			line,
-			column,
-		))
+// newMatchFinding creates the finding of the match. Matches of the whole file, like the ones of NotMatch rules
+// without anchor, are reported as file level findings
+func (r *Rule) newMatchFinding(file *File, m match) engine.Finding {
+	if m.wholeFile {
+		return r.newFinding("", engine.Location{Filename: file.RelativePath, FileLevel: true})
 	}
 
-	return findings
+	return r.newFinding(file.ExtractSample(m.start), file.FindLocation(m.start, m.end))
 }
 
 // newFinding create a new finding with the information of the vulnerability obtained from the file
-func (r *Rule) newFinding(filename, codeSample string, line, column int) engine.Finding {
+func (r *Rule) newFinding(codeSample string, location engine.Location) engine.Finding {
 	return engine.Finding{
-		ID:          r.ID,
-		Name:        r.Name,
-		Severity:    r.Severity,
-		Confidence:  r.Confidence,
-		Description: r.Description,
-		CodeSample:  codeSample,
-		SourceLocation: engine.Location{
-			Filename: filename,
-			Line:     line,
-			Column:   column,
-		},
-	}
-}
-
-// isBinary verify if the file being analyzed is a binary file
-func (r *Rule) isBinary(content []byte) bool {
-	// Ignore Linux binaries
-	if bytes.Equal(content[:4], elfMagicNumber) {
-		return true
+		ID:             r.ID,
+		Name:           r.Name,
+		Severity:       r.Severity,
+		Confidence:     r.Confidence,
+		Description:    r.Description,
+		CodeSample:     codeSample,
+		SourceLocation: location,
+		Metadata:       &r.Metadata,
 	}
-
-	// Ignore Windows binaries
-	if bytes.Equal(content[:2], peMagicBytes) {
-		return true
-	}
-
-	return false
 }
@@ -15,11 +15,17 @@
 package text
 
 import (
+	"context"
+	"io/fs"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+
+	engine "github.com/ZupIT/horusec-engine"
 )
 
 func TestRun(t *testing.T) {
@@ -169,3 +175,170 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRunFile(t *testing.T) {
+	t.Run("Should share the same text file between rules analyzing the same file", func(t *testing.T) {
+		file := engine.NewFile("main.py", []byte("secret = 'abc'\npassword = 'abc'\n"))
+
+		secretRule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`secret =`)}}
+		passwordRule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`password =`)}}
+
+		findings, err := secretRule.RunFile(file)
+		assert.NoError(t, err)
+		assert.Len(t, findings, 1)
+
+		firstTextFile, err := getTextFile(file)
+		assert.NoError(t, err)
+
+		findings, err = passwordRule.RunFile(file)
+		assert.NoError(t, err)
+		assert.Len(t, findings, 1)
+
+		secondTextFile, err := getTextFile(file)
+		assert.NoError(t, err)
+		assert.Same(t, firstTextFile, secondTextFile)
+	})
+}
+
+func TestRunFileWithBinaryAndSmallFiles(t *testing.T) {
+	rule := &Rule{Type: NotMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`should-not-match`)}}
+
+	testCases := []struct {
+		name             string
+		content          []byte
+		expectedFindings int
+	}{
+		{name: "Should not panic with empty files", content: []byte{}, expectedFindings: 1},
+		{name: "Should not panic with files smaller than 4 bytes", content: []byte("ab"), expectedFindings: 1},
+		{name: "Should ignore binary files", content: []byte("\x7fELF\x02\x01"), expectedFindings: 0},
+		{name: "Should ignore images", content: []byte("GIF89a"), expectedFindings: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			findings, err := rule.RunFile(engine.NewFile("file", testCase.content))
+			assert.NoError(t, err)
+			assert.Len(t, findings, testCase.expectedFindings)
+		})
+	}
+}
+
+func TestRunFileWithEngineSniffSize(t *testing.T) {
+	t.Run("Should analyze the files that the engine considers text with its sniff size", func(t *testing.T) {
+		rule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`md5\(\)`)}}
+		content := []byte("md5()\n" + strings.Repeat("a", 500) + "\x00")
+		eng := engine.NewEngineWithOptions(1, []string{".go"}, engine.WithBinarySniffSize(100))
+
+		findings, err := eng.RunContent(context.Background(), "main.go", content, rule)
+		assert.NoError(t, err)
+		assert.Len(t, findings, 1)
+	})
+}
+
+func TestRunFileLocations(t *testing.T) {
+	t.Run("Should report the start and end of the match", func(t *testing.T) {
+		rule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`MessageDigest\.getInstance\("MD5"\)`)}}
+		content := "class A {\n  MessageDigest md = MessageDigest.getInstance(\"MD5\");\n}"
+
+		findings, err := rule.RunFile(engine.NewFile("A.java", []byte(content)))
+		assert.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			location := findings[0].SourceLocation
+			assert.Equal(t, 2, location.Line)
+			assert.Equal(t, 22, location.Column)
+			assert.Equal(t, 2, location.EndLine)
+			assert.Equal(t, 54, location.EndColumn)
+			assert.Equal(t, `MessageDigest.getInstance("MD5")`, content[location.Offset:location.EndOffset])
+		}
+	})
+}
+
+func TestRunFileMetadata(t *testing.T) {
+	t.Run("Should reference the complete rule metadata in the findings", func(t *testing.T) {
+		rule := &Rule{
+			Metadata: engine.Metadata{
+				ID:            "HS-JAVA-1",
+				Name:          "Weak hash",
+				Description:   "MD5 is a weak hash algorithm",
+				Severity:      "MEDIUM",
+				Confidence:    "HIGH",
+				CWEs:          []string{"CWE-327", "CWE-328"},
+				CVEs:          []string{"CVE-2004-2761"},
+				Mitigation:    "Use SHA-256 or stronger",
+				Reference:     "https://cwe.mitre.org/data/definitions/327.html",
+				SafeExample:   `MessageDigest.getInstance("SHA-256")`,
+				UnsafeExample: `MessageDigest.getInstance("MD5")`,
+			},
+			Type:        OrMatch,
+			Expressions: []*regexp.Regexp{regexp.MustCompile(`MessageDigest\.getInstance\("MD5"\)`)},
+		}
+
+		findings, err := rule.RunFile(engine.NewFile("A.java", []byte(`MessageDigest.getInstance("MD5");`)))
+		assert.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, rule.Metadata, *findings[0].Metadata)
+			assert.Equal(t, "HS-JAVA-1", findings[0].ID)
+		}
+	})
+}
+
+func TestRunFileExtensions(t *testing.T) {
+	testcases := []struct {
+		name       string
+		extensions []string
+		path       string
+		expected   int
+	}{
+		{name: "Should analyze any file when the rule has no extensions", path: "A.kt", expected: 1},
+		{
+			name:       "Should analyze files with the rule extensions",
+			extensions: []string{".kt", ".java"}, path: "A.java", expected: 1,
+		},
+		{
+			name:       "Should compare the extensions with the same case, like the engine",
+			extensions: []string{".java"}, path: "A.JAVA", expected: 0,
+		},
+		{
+			name:       "Should analyze any file with the any extension",
+			extensions: []string{engine.AcceptAnyExtension}, path: "A", expected: 1,
+		},
+		{name: "Should ignore files without the rule extensions", extensions: []string{".java"}, path: "A.kt", expected: 0},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{
+				Type:        OrMatch,
+				Expressions: []*regexp.Regexp{regexp.MustCompile(`getInstance\("MD5"\)`)},
+				Extensions:  tt.extensions,
+			}
+
+			findings, err := rule.RunFile(engine.NewFile(tt.path, []byte(`getInstance("MD5")`)))
+			assert.NoError(t, err)
+			assert.Len(t, findings, tt.expected)
+		})
+	}
+}
+
+func TestRunFS(t *testing.T) {
+	rule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`getInstance\("MD5"\)`)}}
+	fsys := fstest.MapFS{"src/A.java": {Data: []byte("class A {\n  getInstance(\"MD5\");\n}")}}
+
+	t.Run("Should read the file from the file system", func(t *testing.T) {
+		findings, err := rule.RunFS(fsys, "src/A.java")
+		assert.NoError(t, err)
+
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, "src/A.java", findings[0].SourceLocation.Filename)
+			assert.Equal(t, 2, findings[0].SourceLocation.Line)
+		}
+	})
+
+	t.Run("Should return error when the file doesn't exist", func(t *testing.T) {
+		_, err := rule.RunFS(fsys, "src/B.java")
+
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
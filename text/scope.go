@@ -0,0 +1,223 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+// ScopeType represents where the other conditions of an All must be satisfied around each match of its reported
+// condition. The blocks are found only by the text of the file, so braces and indentation inside strings and
+// comments are counted too
+type ScopeType int
+
+const (
+	// FileScope allows the conditions to be satisfied anywhere in the file
+	FileScope ScopeType = iota
+
+	// LineScope requires the conditions to be satisfied on the lines of the match
+	LineScope
+
+	// WindowScope requires the conditions to be satisfied up to Window lines before or after the lines of the match
+	WindowScope
+
+	// BlockScope requires the conditions to be satisfied in the innermost block delimited by braces that contains the
+	// match, like the body of a Java or JavaScript function, or in the whole file if there is no such block
+	BlockScope
+
+	// IndentScope requires the conditions to be satisfied in the innermost block delimited by indentation that
+	// contains the match, like a Python function, which starts at the line with less indentation above the match and
+	// ends before the next line with the same indentation as this one. Tabs and spaces are counted as one column each
+	IndentScope
+)
+
+// span is a region of the file content, the end index is exclusive
+type span struct {
+	start int
+	end   int
+}
+
+// contains checks if the match is inside the span
+func (s span) contains(m match) bool {
+	return m.start >= s.start && m.end <= s.end
+}
+
+// intersect returns the region that is inside both spans
+func (s span) intersect(other span) span {
+	if other.start > s.start {
+		s.start = other.start
+	}
+
+	if other.end < s.end {
+		s.end = other.end
+	}
+
+	return s
+}
+
+// scopeSpan returns the region of the file around the match in which the conditions of the scope must be satisfied,
+// window is the number of lines before and after the match used by WindowScope
+func scopeSpan(file *File, scope ScopeType, window int, m match) span {
+	if m.wholeFile || scope == FileScope {
+		return span{start: 0, end: len(file.Content)}
+	}
+
+	if scope == BlockScope {
+		return findBraceBlock(file.Content, m.start)
+	}
+
+	return file.linesScopeSpan(scope, window, m)
+}
+
+// linesScopeSpan returns the region around the lines of the match used by the LineScope, WindowScope and IndentScope
+func (f *File) linesScopeSpan(scope ScopeType, window int, m match) span {
+	firstLine, lastLine := f.matchLines(m)
+
+	if scope == IndentScope {
+		return f.findIndentBlock(firstLine)
+	}
+
+	if scope == WindowScope {
+		return f.linesSpan(firstLine-window, lastLine+window)
+	}
+
+	return f.linesSpan(firstLine, lastLine)
+}
+
+// matchLines returns the indexes of the first and the last lines of the match, an empty match is on a single line
+func (f *File) matchLines(m match) (firstLine, lastLine int) {
+	end := m.end
+	if end > m.start {
+		end--
+	}
+
+	return f.findLineIndex(m.start), f.findLineIndex(end)
+}
+
+// linesSpan returns the region from the beginning of the first line until the end of the last line, including its
+// line break. Lines out of the file are ignored
+func (f *File) linesSpan(firstLine, lastLine int) span {
+	if firstLine < 0 {
+		firstLine = 0
+	}
+
+	result := span{start: f.lineStarts[firstLine], end: len(f.Content)}
+	if lastLine+1 < len(f.lineStarts) {
+		result.end = f.lineStarts[lastLine+1]
+	}
+
+	return result
+}
+
+// findBraceBlock returns the region between the unbalanced braces around the index, including them. The start and
+// the end of the file are used when the opening or closing brace isn't found
+func findBraceBlock(content []byte, index int) span {
+	return span{start: findOpeningBrace(content, index), end: findClosingBrace(content, index)}
+}
+
+// findOpeningBrace returns the index of the unbalanced opening brace before the index, or 0 when there is none
+func findOpeningBrace(content []byte, index int) int {
+	for depth, i := 0, index-1; i >= 0; i-- {
+		switch content[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth == 0 {
+				return i
+			}
+
+			depth--
+		}
+	}
+
+	return 0
+}
+
+// findClosingBrace returns the index after the unbalanced closing brace from the index, or the length of the content
+// when there is none
+func findClosingBrace(content []byte, index int) int {
+	for depth, i := 0, index; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i + 1
+			}
+
+			depth--
+		}
+	}
+
+	return len(content)
+}
+
+// findIndentBlock returns the region of the innermost indentation block that contains the line, from its header
+// line, which is the first line above with less indentation, until the last line indented more than the header.
+// Blank lines don't end the block, and the whole file is returned for lines that aren't indented
+func (f *File) findIndentBlock(line int) span {
+	header, ok := f.findIndentHeader(line)
+	if !ok {
+		return span{start: 0, end: len(f.Content)}
+	}
+
+	return f.linesSpan(header, f.findIndentEnd(header, line))
+}
+
+// findIndentHeader returns the first line above the line with less indentation than it, it returns false when the
+// line isn't indented or there is no such line
+func (f *File) findIndentHeader(line int) (int, bool) {
+	indent, _ := f.lineIndent(line)
+
+	for previous := line - 1; previous >= 0 && indent > 0; previous-- {
+		if previousIndent, blank := f.lineIndent(previous); !blank && previousIndent < indent {
+			return previous, true
+		}
+	}
+
+	return 0, false
+}
+
+// findIndentEnd returns the last line from the line that is indented more than the header, skipping the blank lines
+func (f *File) findIndentEnd(header, line int) int {
+	headerIndent, _ := f.lineIndent(header)
+	last := line
+
+	for next := line + 1; next < len(f.lineStarts); next++ {
+		if nextIndent, blank := f.lineIndent(next); !blank {
+			if nextIndent <= headerIndent {
+				break
+			}
+
+			last = next
+		}
+	}
+
+	return last
+}
+
+// lineIndent returns the number of spaces and tabs at the beginning of the line, and if it's blank
+func (f *File) lineIndent(line int) (indent int, blank bool) {
+	lineSpan := f.linesSpan(line, line)
+
+	for index := lineSpan.start; index < lineSpan.end; index++ {
+		switch f.Content[index] {
+		case ' ', '\t':
+			indent++
+		case '\r', '\n':
+			return indent, true
+		default:
+			return indent, false
+		}
+	}
+
+	return indent, true
+}
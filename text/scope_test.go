@@ -0,0 +1,194 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+const scopeJavaScriptSample = `app.get("/a", function (req, res) {
+  const query = req.query.q;
+  log(query);
+});
+
+app.get("/b", function (req, res) {
+  eval(defaults);
+  res.send(req.query.q);
+});
+
+eval(req.query.q);
+`
+
+const scopePythonSample = `def a(request):
+    value = request.args.get("q")
+
+    return value
+
+def b(request):
+    data = {}
+    if data:
+        pass
+    return eval(data)
+`
+
+// nolint:funlen // table of scopes
+func TestRunFileWithScope(t *testing.T) {
+	testcases := []struct {
+		name     string
+		content  string
+		all      *All
+		expected []int
+	}{
+		{
+			name:     "Should report the matches with the other conditions anywhere in the file without scope",
+			content:  scopeJavaScriptSample,
+			all:      &All{Conditions: []Condition{regex(`eval\(`), regex(`req\.query`)}},
+			expected: []int{7, 11},
+		},
+		{
+			name:     "Should report only the matches with the other conditions on the same line",
+			content:  scopeJavaScriptSample,
+			all:      &All{Conditions: []Condition{regex(`eval\(`), regex(`req\.query`)}, Scope: LineScope},
+			expected: []int{11},
+		},
+		{
+			name:    "Should report only the matches with the other conditions inside the window of lines",
+			content: scopeJavaScriptSample,
+			all: &All{
+				Conditions: []Condition{regex(`eval\(`), regex(`res\.send`)},
+				Scope:      WindowScope,
+				Window:     1,
+			},
+			expected: []int{7},
+		},
+		{
+			name:     "Should report only the matches with the other conditions inside the same brace block",
+			content:  scopeJavaScriptSample,
+			all:      &All{Conditions: []Condition{regex(`req\.query`), regex(`eval\(`)}, Scope: BlockScope},
+			expected: []int{8, 11},
+		},
+		{
+			name:    "Should use the whole file as block for the matches outside braces",
+			content: scopeJavaScriptSample,
+			all: &All{
+				Conditions: []Condition{regex(`eval\(req`), regex(`const query`)},
+				Scope:      BlockScope,
+			},
+			expected: []int{11},
+		},
+		{
+			name:    "Should report the matches without the negated conditions inside the same brace block",
+			content: scopeJavaScriptSample,
+			all: &All{
+				Conditions: []Condition{regex(`req\.query`), &Not{Condition: regex(`log\(`)}},
+				Scope:      BlockScope,
+			},
+			expected: []int{8},
+		},
+		{
+			name:     "Should report only the matches with the other conditions inside the same indentation block",
+			content:  scopePythonSample,
+			all:      &All{Conditions: []Condition{regex(`request\.`), regex(`return`)}, Scope: IndentScope},
+			expected: []int{2},
+		},
+		{
+			name:    "Should not include the lines after the nested indentation block",
+			content: scopePythonSample,
+			all: &All{
+				Conditions: []Condition{regex(`pass`), regex(`eval`)},
+				Scope:      IndentScope,
+			},
+			expected: []int{},
+		},
+		{
+			name:    "Should report only the first match with the other conditions in the scope with first match only",
+			content: scopeJavaScriptSample,
+			all: &All{
+				Conditions:     []Condition{regex(`req\.query`), regex(`const|res\.send`)},
+				Scope:          LineScope,
+				FirstMatchOnly: true,
+			},
+			expected: []int{2},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{Condition: tt.all}
+
+			findings, err := rule.RunFile(engine.NewFile("main.js", []byte(tt.content)))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, findingsLines(findings))
+		})
+	}
+}
+
+func TestRunFileAndMatchWithScope(t *testing.T) {
+	t.Run("Should report each match of the first expression with the others in the scope", func(t *testing.T) {
+		rule := &Rule{
+			Type:        AndMatch,
+			Expressions: []*regexp.Regexp{regexp.MustCompile(`req\.query`), regexp.MustCompile(`res\.send`)},
+			Scope:       BlockScope,
+		}
+
+		findings, err := rule.RunFile(engine.NewFile("main.js", []byte(scopeJavaScriptSample)))
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{8, 11}, findingsLines(findings))
+	})
+
+	t.Run("Should return an error for scopes of other match types", func(t *testing.T) {
+		rule := &Rule{Type: OrMatch, Expressions: []*regexp.Regexp{regexp.MustCompile(`a`)}, Scope: LineScope}
+
+		_, err := rule.RunFile(engine.NewFile("main.js", []byte("a")))
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return an error for invalid windows", func(t *testing.T) {
+		rule := &Rule{Condition: &All{Conditions: []Condition{regex(`a`)}, Scope: WindowScope, Window: -1}}
+
+		_, err := rule.RunFile(engine.NewFile("main.js", []byte("a")))
+		assert.Error(t, err)
+	})
+}
+
+func TestFindBraceBlock(t *testing.T) {
+	content := []byte("a { b { c } d } e")
+
+	testcases := []struct {
+		name     string
+		index    int
+		expected string
+	}{
+		{name: "Should return the innermost block", index: 8, expected: "{ c }"},
+		{name: "Should skip the nested blocks", index: 12, expected: "{ b { c } d }"},
+		{name: "Should return the whole content outside blocks", index: 16, expected: "a { b { c } d } e"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			block := findBraceBlock(content, tt.index)
+
+			assert.Equal(t, tt.expected, string(content[block.start:block.end]))
+		})
+	}
+}
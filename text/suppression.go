@@ -0,0 +1,166 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+// suppressFindings sets the suppression of each finding with an inline annotation on the finding line or on the line
+// above, when the line above only has a comment. The annotations are only recognized right after the beginning of a
+// comment in the syntax of the file language, outside string literals. Findings of the whole file, without a line,
+// can't be suppressed
+func suppressFindings(file *File, findings []engine.Finding) {
+	for index := range findings {
+		if line := findings[index].SourceLocation.Line; line > 0 {
+			findings[index].Suppression = file.findSuppression(line, findings[index].ID)
+		}
+	}
+}
+
+// findSuppression returns the annotation that suppresses the rule in the 1-based line, or nil if there is none
+func (f *File) findSuppression(line int, ruleID string) *engine.Suppression {
+	if suppression := f.findLineSuppression(line-1, ruleID); suppression != nil {
+		return suppression
+	}
+
+	if line < 2 || !f.isCommentLine(line-2) {
+		return nil
+	}
+
+	return f.findLineSuppression(line-2, ruleID)
+}
+
+// findLineSuppression returns the annotation of the 0-based line index when it suppresses the rule, or nil otherwise
+func (f *File) findLineSuppression(lineIndex int, ruleID string) *engine.Suppression {
+	if suppression := f.parseSuppression(lineIndex); suppression != nil && suppression.suppresses(ruleID) {
+		return suppression.Suppression
+	}
+
+	return nil
+}
+
+// suppression is a parsed annotation, which can suppress all rules or only the ones informed
+type suppression struct {
+	*engine.Suppression
+}
+
+// suppresses checks if the annotation suppresses the rule
+func (s suppression) suppresses(ruleID string) bool {
+	if len(s.RuleIDs) == 0 {
+		return true
+	}
+
+	for _, id := range s.RuleIDs {
+		if strings.EqualFold(id, ruleID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSuppression parses the first annotation of the 0-based line index, it returns nil if the line doesn't have one
+func (f *File) parseSuppression(lineIndex int) *suppression {
+	start, end := f.lineBounds(lineIndex)
+	line := f.Content[start:end]
+
+	for _, match := range getCommentSyntax(f.Name).annotation.FindAllSubmatchIndex(line, -1) {
+		if isAnnotationEnd(line, match[1]) && !isInsideString(line[:match[0]]) {
+			return f.newSuppression(line, start, match)
+		}
+	}
+
+	return nil
+}
+
+// newSuppression creates the suppression of the annotation matched in the line, which begins at the start index of
+// the file content
+func (f *File) newSuppression(line []byte, start int, match []int) *suppression {
+	return &suppression{&engine.Suppression{
+		Kind:     strings.ToLower(string(line[match[2]:match[3]])),
+		RuleIDs:  parseSuppressedRuleIDs(string(line[match[4]:match[5]])),
+		Location: f.FindLocation(start+match[0], start+match[1]),
+	}}
+}
+
+// isAnnotationEnd checks that the annotation isn't followed by other characters of a word, like in
+// "horusec-ignored", the end of a block comment can follow it
+func isAnnotationEnd(line []byte, end int) bool {
+	if end == len(line) || bytes.HasPrefix(line[end:], []byte("-->")) {
+		return true
+	}
+
+	return !isWordRune(rune(line[end]))
+}
+
+// isWordRune checks if the rune can be part of the word of an annotation, like the "d" of "horusec-ignored"
+func isWordRune(r rune) bool {
+	return r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseSuppressedRuleIDs parses the rule IDs informed after the annotation. Rule IDs always have a digit (e.g.
+// HS-JAVA-1 or G101), so the parse stops at the first word without digits, which is part of a justification
+func parseSuppressedRuleIDs(value string) []string {
+	var ids []string
+
+	for _, field := range strings.FieldsFunc(value, isRuleIDSeparator) {
+		if !strings.ContainsAny(field, "0123456789") {
+			break
+		}
+
+		ids = append(ids, field)
+	}
+
+	return ids
+}
+
+// isRuleIDSeparator checks if the rune separates the rule IDs of an annotation
+func isRuleIDSeparator(r rune) bool {
+	return r == ',' || r == ':' || unicode.IsSpace(r)
+}
+
+// isCommentLine checks if the 0-based line index only has a comment
+func (f *File) isCommentLine(lineIndex int) bool {
+	start, end := f.lineBounds(lineIndex)
+	line := strings.TrimSpace(string(f.Content[start:end]))
+
+	for _, prefix := range getCommentSyntax(f.Name).prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lineBounds returns the indexes where the 0-based line index begins and ends, without its line terminator
+func (f *File) lineBounds(lineIndex int) (start, end int) {
+	start, end = f.lineStarts[lineIndex], len(f.Content)
+
+	if lineIndex+1 < len(f.lineStarts) {
+		end = f.lineStarts[lineIndex+1]
+	}
+
+	for end > start && (f.Content[end-1] == '\n' || f.Content[end-1] == '\r') {
+		end--
+	}
+
+	return start, end
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 ZUP IT SERVICOS EM TECNOLOGIA E INOVACAO SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	engine "github.com/ZupIT/horusec-engine"
+)
+
+func newSuppressionTestRule() *Rule {
+	return &Rule{
+		Metadata:    engine.Metadata{ID: "HS-JAVA-1"},
+		Type:        OrMatch,
+		Expressions: []*regexp.Regexp{regexp.MustCompile(`getInstance\("MD5"\)`)},
+	}
+}
+
+// nolint:funlen // table of annotations in the syntax of many languages
+func TestRunFileSuppressions(t *testing.T) {
+	testcases := []struct {
+		name       string
+		filename   string
+		content    string
+		suppressed bool
+		kind       string
+		ruleIDs    []string
+	}{
+		{
+			name:       "Should suppress with horusec-ignore on the same line",
+			content:    `md.getInstance("MD5"); // horusec-ignore`,
+			suppressed: true, kind: engine.SuppressionHorusecIgnore,
+		},
+		{
+			name:       "Should suppress with nosec and the rule ID on the line above",
+			filename:   "a.py",
+			content:    "# nosec HS-JAVA-1\nmd.getInstance(\"MD5\")",
+			suppressed: true, kind: engine.SuppressionNoSec, ruleIDs: []string{"HS-JAVA-1"},
+		},
+		{
+			name:       "Should suppress with many rule IDs and a justification",
+			content:    "md.getInstance(\"MD5\") /* #nosec: HS-GO-2, hs-java-1 -- legacy checksum */",
+			suppressed: true, kind: engine.SuppressionNoSec, ruleIDs: []string{"HS-GO-2", "hs-java-1"},
+		},
+		{
+			name:       "Should suppress with an annotation in a SQL comment",
+			filename:   "a.sql",
+			content:    "-- horusec-ignore\nmd.getInstance(\"MD5\")",
+			suppressed: true, kind: engine.SuppressionHorusecIgnore,
+		},
+		{
+			name:       "Should suppress with an annotation in an HTML comment",
+			filename:   "a.html",
+			content:    "<!-- nosec -->\nmd.getInstance(\"MD5\")",
+			suppressed: true, kind: engine.SuppressionNoSec,
+		},
+		{
+			name:       "Should suppress with an annotation in an INI comment",
+			filename:   "a.ini",
+			content:    "; HORUSEC-IGNORE\nmd.getInstance(\"MD5\")",
+			suppressed: true, kind: engine.SuppressionHorusecIgnore,
+		},
+		{
+			name:       "Should suppress with the comments of any language in files of unknown languages",
+			filename:   "Dockerfile",
+			content:    "RUN md.getInstance(\"MD5\") # nosec",
+			suppressed: true, kind: engine.SuppressionNoSec,
+		},
+		{
+			name:       "Should suppress with an annotation in a block comment continuation line",
+			content:    "/*\n * horusec-ignore HS-JAVA-1 reviewed by the security team */\nmd.getInstance(\"MD5\")",
+			suppressed: true, kind: engine.SuppressionHorusecIgnore, ruleIDs: []string{"HS-JAVA-1"},
+		},
+		{
+			name:    "Should not suppress with the annotation of another rule",
+			content: `md.getInstance("MD5"); // nosec HS-JAVA-2`,
+		},
+		{
+			name:    "Should not suppress with an annotation on a code line above",
+			content: "call(); // horusec-ignore\nmd.getInstance(\"MD5\")",
+		},
+		{
+			name:    "Should not suppress with an annotation two lines above",
+			content: "// horusec-ignore\n\nmd.getInstance(\"MD5\")",
+		},
+		{
+			name:    "Should not suppress with an annotation outside of comments",
+			content: `md.getInstance("MD5"); log("nosec")`,
+		},
+		{
+			name:    "Should not suppress with an annotation inside a string literal",
+			content: `String url = "http://x#nosec"; log("// nosec"); md.getInstance("MD5");`,
+		},
+		{
+			name:    "Should not suppress with the comments of other languages",
+			content: `md.getInstance("MD5"); # nosec`,
+		},
+		{
+			name:    "Should not suppress with words that begin with the annotation",
+			content: `md.getInstance("MD5"); // horusec-ignored, nosecure`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := tt.filename
+			if filename == "" {
+				filename = "A.java"
+			}
+
+			findings, err := newSuppressionTestRule().RunFile(engine.NewFile(filename, []byte(tt.content)))
+			require.NoError(t, err)
+			require.NotEmpty(t, findings)
+
+			for _, finding := range findings {
+				if !tt.suppressed {
+					assert.Nil(t, finding.Suppression)
+
+					continue
+				}
+
+				require.NotNil(t, finding.Suppression)
+				assert.Equal(t, tt.kind, finding.Suppression.Kind)
+				assert.Equal(t, tt.ruleIDs, finding.Suppression.RuleIDs)
+			}
+		})
+	}
+}
+
+func TestRunFileSuppressionLocation(t *testing.T) {
+	t.Run("Should record the location of the annotation", func(t *testing.T) {
+		content := "x();\r\n    // nosec HS-JAVA-1\r\n    md.getInstance(\"MD5\");"
+
+		findings, err := newSuppressionTestRule().RunFile(engine.NewFile("A.java", []byte(content)))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+		require.NotNil(t, findings[0].Suppression)
+
+		assert.Equal(t, engine.Location{
+			Filename: "A.java", Line: 2, Column: 5, EndLine: 2, EndColumn: 23, Offset: 10, EndOffset: 28,
+		}, findings[0].Suppression.Location)
+		assert.Equal(t, 3, findings[0].SourceLocation.Line)
+	})
+
+	t.Run("Should not suppress findings of the whole file", func(t *testing.T) {
+		rule := newSuppressionTestRule()
+		rule.Type = NotMatch
+
+		findings, err := rule.RunFile(engine.NewFile("A.java", []byte("// horusec-ignore")))
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+		assert.Nil(t, findings[0].Suppression)
+	})
+}